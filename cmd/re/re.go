@@ -1,25 +1,46 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	re "github.com/konradreiche/re/internal"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
-	commander *re.Command
-	pr        int
-	lines     int
-	message   string
+	commander  *re.Command
+	pr         int
+	lines      int
+	message    string
+	side       string
+	event      string
+	format     string
+	check      string
+	follow     bool
+	failedOnly bool
+	withToken  bool
+	noCache    bool
+	offline    bool
+
+	watchOptions    re.WatchOptions
+	checkoutOptions re.CheckoutOptions
+
+	squash, rebase, mergeCommit bool
+	autoMerge, deleteBranch     bool
 )
 
 var rootCmd = &cobra.Command{
 	Use:               "re",
 	Short:             "📬 re (again) – review, respond, rethink",
 	PersistentPreRunE: newCommander,
+	PersistentPostRun: warnQuota,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cmd.Help()
 	},
@@ -41,7 +62,143 @@ var commentCmd = &cobra.Command{
 	Args:    cobra.ExactArgs(1),
 	PreRunE: parseIntArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return commander.ApprovePullRequest(cmd.Context(), pr, message)
+		return commander.CommentPullRequest(cmd.Context(), pr, message)
+	},
+}
+
+var requestChangesCmd = &cobra.Command{
+	Use:     "request-changes",
+	Short:   "Request changes on a pull request",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.RequestChanges(cmd.Context(), pr, message)
+	},
+}
+
+var dismissCmd = &cobra.Command{
+	Use:     "dismiss <pr> <review-id>",
+	Short:   "Dismiss a review of a pull request",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.DismissReview(cmd.Context(), pr, args[1], message)
+	},
+}
+
+var replyCmd = &cobra.Command{
+	Use:     "reply <pr> <comment-id>",
+	Short:   "Reply to a review comment of a pull request",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.ReplyToComment(cmd.Context(), pr, args[1], message)
+	},
+}
+
+var resolveCmd = &cobra.Command{
+	Use:     "resolve <pr> <thread>",
+	Short:   "Resolve a review thread of a pull request",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.ResolveThread(cmd.Context(), pr, args[1], true)
+	},
+}
+
+var unresolveCmd = &cobra.Command{
+	Use:     "unresolve <pr> <thread>",
+	Short:   "Unresolve a review thread of a pull request",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.ResolveThread(cmd.Context(), pr, args[1], false)
+	},
+}
+
+var reviewCommentCmd = &cobra.Command{
+	Use:     "review-comment <pr> <path>:<line>[-<line>]",
+	Short:   "Comment on lines of a pull request's diff",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.CommentOnLines(cmd.Context(), pr, args[1], side, message)
+	},
+}
+
+var draftCmd = &cobra.Command{
+	Use:   "draft",
+	Short: "Batch comments into a pending review",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var draftAddCmd = &cobra.Command{
+	Use:     "add <pr> <path>:<line>[-<line>]",
+	Short:   "Add a line comment to the pending review",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.AddDraftComment(cmd.Context(), pr, args[1], side, message)
+	},
+}
+
+var draftListCmd = &cobra.Command{
+	Use:     "ls <pr>",
+	Short:   "List the comments of the pending review",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.PrintDraft(cmd.Context(), pr)
+	},
+}
+
+var draftEditCmd = &cobra.Command{
+	Use:     "edit <pr> <comment>",
+	Short:   "Replace the message of a pending comment",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			return err
+		}
+		return commander.EditDraftComment(cmd.Context(), pr, index, message)
+	},
+}
+
+var draftRemoveCmd = &cobra.Command{
+	Use:     "rm <pr> <comment>",
+	Short:   "Drop a pending comment",
+	Args:    cobra.ExactArgs(2),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			return err
+		}
+		return commander.DeleteDraftComment(cmd.Context(), pr, index)
+	},
+}
+
+var draftSubmitCmd = &cobra.Command{
+	Use:     "submit <pr>",
+	Short:   "Submit the pending review",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.SubmitDraft(cmd.Context(), pr, event, message)
+	},
+}
+
+var draftDiscardCmd = &cobra.Command{
+	Use:     "discard <pr>",
+	Short:   "Discard the pending review",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.DiscardDraft(cmd.Context(), pr)
 	},
 }
 
@@ -74,12 +231,65 @@ var diffCmd = &cobra.Command{
 }
 
 var checkoutCmd = &cobra.Command{
-	Use:     "checkout",
-	Short:   "Locally checkout a pull request",
+	Use:   "checkout <pr>",
+	Short: "Locally checkout a pull request",
+	Long: `Locally checkout a pull request.
+
+The pull request is checked out into a branch named after its head branch,
+which tracks the head branch so that git pull and git push work. If the head
+branch is in a fork that maintainers can modify, a remote named after the owner
+of the fork is added.`,
 	Args:    cobra.ExactArgs(1),
 	PreRunE: parseIntArg,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return commander.CheckoutPullRequest(cmd.Context(), pr)
+		return commander.CheckoutPullRequest(cmd.Context(), pr, checkoutOptions)
+	},
+}
+
+var stackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Show the stack of pull requests of the current branch",
+	Long: `Show the stack of pull requests of the current branch.
+
+A stack is a chain of local branches, each based on the one below it, with the
+bottom branch based on the default branch. The pull request of each branch is
+based on the branch below it, so that it only shows the changes of that branch.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.PrintStack(cmd.Context())
+	},
+}
+
+var stackSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Push the stack and create or update a pull request per branch",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.SubmitStack(cmd.Context())
+	},
+}
+
+var stackRestackCmd = &cobra.Command{
+	Use:   "restack",
+	Short: "Rebase each branch of the stack onto the branch below it",
+	Long: `Rebase each branch of the stack onto the branch below it.
+
+Run it after a branch in the stack changed or its pull request was merged. The
+branches above a merged pull request are rebased onto the default branch. Run
+re stack submit afterwards to push the rebased branches.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.RestackStack(cmd.Context())
+	},
+}
+
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Manage the worktrees of checked out pull requests",
+}
+
+var worktreePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the worktrees and branches of merged pull requests",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.PruneWorktrees(cmd.Context())
 	},
 }
 
@@ -117,6 +327,85 @@ var readyCmd = &cobra.Command{
 	},
 }
 
+var checksCmd = &cobra.Command{
+	Use:     "checks <pr>",
+	Short:   "List the CI checks of a pull request",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.PrintChecks(cmd.Context(), pr)
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:     "logs <pr>",
+	Short:   "Print the log of a failing check of a pull request",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.PrintLogs(cmd.Context(), pr, check, follow, lines)
+	},
+}
+
+var rerunCmd = &cobra.Command{
+	Use:     "rerun <pr>",
+	Short:   "Re-run the failed CI checks of a pull request",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.RerunChecks(cmd.Context(), pr, failedOnly)
+	},
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <pr>",
+	Short: "Wait for the CI checks of a pull request to complete",
+	Long: `Wait for the CI checks of a pull request to complete.
+
+Exits with status 0 if all checks passed, 2 if a check failed and 3 if the
+checks did not complete within the timeout.`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.WatchPullRequest(cmd.Context(), pr, watchOptions)
+	},
+}
+
+var mergeCmd = &cobra.Command{
+	Use:     "merge <pr>",
+	Short:   "Merge a pull request",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := re.MergeOptions{
+			Method:       re.PullRequestMergeMethodMerge,
+			Auto:         autoMerge,
+			DeleteBranch: deleteBranch,
+		}
+		switch {
+		case squash:
+			opts.Method = re.PullRequestMergeMethodSquash
+		case rebase:
+			opts.Method = re.PullRequestMergeMethodRebase
+		}
+		return commander.MergePullRequest(cmd.Context(), pr, opts)
+	},
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Store pull requests for offline use and submit queued comments",
+	Long: `Sync submits the comments queued with --offline and then stores the open
+pull requests, their conversations and diffs, as well as the notifications, so
+that ls, show, diff and inbox can read them with --offline.
+
+The repositories listed under repos in the configuration file are synced, or
+the repository of the working directory if none are listed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.Sync(cmd.Context())
+	},
+}
+
 var showCmd = &cobra.Command{
 	Use:     "show",
 	Short:   "Display a pull requst",
@@ -127,6 +416,16 @@ var showCmd = &cobra.Command{
 	},
 }
 
+var tuiCmd = &cobra.Command{
+	Use:     "tui",
+	Short:   "Review a pull request in an interactive interface",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: parseIntArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return commander.ReviewInteractively(cmd.Context(), pr)
+	},
+}
+
 var reviewCmd = &cobra.Command{
 	Use:   "review",
 	Short: "Show pull requests that require your review",
@@ -135,19 +434,137 @@ var reviewCmd = &cobra.Command{
 	},
 }
 
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the access tokens used to talk to GitHub",
+	// Logging in must work without a valid token, so no client is created.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return nil
+	},
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in with the OAuth device flow or a personal access token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := re.NewConfig()
+		if err != nil {
+			return err
+		}
+		store, err := re.NewCredentialStore()
+		if err != nil {
+			return err
+		}
+		var token string
+		switch {
+		case withToken:
+			b, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			token = string(b)
+		case config.OAuthClientID != "":
+			token, err = re.AuthorizeDevice(cmd.Context(), config, os.Stdout)
+			if err != nil {
+				return err
+			}
+		default:
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return errors.New("no oauth_client_id configured, pass a token with --with-token")
+			}
+			fmt.Printf("Paste a personal access token for %s: ", config.Host)
+			b, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Println()
+			if err != nil {
+				return err
+			}
+			token = string(b)
+		}
+		status, err := re.Login(cmd.Context(), config, store, token)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Logged in to %s as %s, token stored in %s\n", status.Host, status.Login, status.Source)
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored access token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := re.NewConfig()
+		if err != nil {
+			return err
+		}
+		store, err := re.NewCredentialStore()
+		if err != nil {
+			return err
+		}
+		if err := re.Logout(config, store); err != nil {
+			return err
+		}
+		fmt.Printf("Logged out of %s\n", config.Host)
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the user, scopes and expiration of the access token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := re.NewConfig()
+		if err != nil {
+			return err
+		}
+		status, err := re.FetchAuthStatus(cmd.Context(), config)
+		if err != nil {
+			return err
+		}
+		expires := "never"
+		if !status.ExpiresAt.IsZero() {
+			expires = status.ExpiresAt.Local().Format(time.RFC1123)
+		}
+		fmt.Println(status.Host)
+		fmt.Printf("  Logged in as %s (%s)\n", status.Login, status.Source)
+		fmt.Printf("  Scopes:  %s\n", strings.Join(status.Scopes, ", "))
+		fmt.Printf("  Expires: %s\n", expires)
+		return nil
+	},
+}
+
 func newCommander(cmd *cobra.Command, args []string) error {
 	// TODO: Consider using command annotations to store whether a git repository
 	// is required to simplify this logic.
 	requireGit := true
 	switch cmd.Name() {
-	case "re":
+	case "re", "draft":
 		return nil
-	case "review", "inbox":
+	case "review", "inbox", "sync":
 		requireGit = false
 	}
 	cmd.SilenceUsage = true
 	cmd.SilenceErrors = true
-	commands, err := re.NewCommand(cmd.Context(), re.NewConfig(), re.WithRequireGit(requireGit))
+	config, err := re.NewConfig()
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("lines") && config.Lines > 0 {
+		lines = config.Lines
+	}
+	if noCache {
+		config.CacheDir = ""
+	}
+	commands, err := re.NewCommand(cmd.Context(), config,
+		re.WithRequireGit(requireGit),
+		re.WithFormat(format),
+		re.WithOffline(offline),
+	)
 	if err != nil {
 		return err
 	}
@@ -155,6 +572,20 @@ func newCommander(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// warnQuota warns when the API quota is running low, so that scripts running
+// many commands can slow down before requests start failing.
+func warnQuota(cmd *cobra.Command, args []string) {
+	if commander == nil {
+		return
+	}
+	for _, quota := range commander.Quotas() {
+		if quota.Low() {
+			fmt.Fprintf(os.Stderr, "warning: %d of %d GitHub %s API quota left, resets at %s\n",
+				quota.Remaining, quota.Limit, quota.Resource, quota.ResetAt.Local().Format(time.Kitchen))
+		}
+	}
+}
+
 func parseIntArg(cmd *cobra.Command, args []string) error {
 	n, err := strconv.Atoi(args[0])
 	if err != nil {
@@ -165,21 +596,85 @@ func parseIntArg(cmd *cobra.Command, args []string) error {
 }
 
 func main() {
-	rootCmd.PersistentFlags().IntVarP(&lines, "lines", "n", 20, "print up to many lines")
+	rootCmd.PersistentFlags().IntVarP(&lines, "lines", "n", 20, "print up to many lines, 0 prints all")
 	rootCmd.PersistentFlags().StringVarP(&message, "message", "m", "", "provide comment")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "always fetch fresh data instead of using cached API responses")
+	rootCmd.PersistentFlags().StringVar(&format, "format", "text", "output format: text, json, ndjson, tsv or template=<go-template>")
+
+	reviewCommentCmd.Flags().StringVar(&side, "side", re.SideRight, "side of the diff to comment on: LEFT or RIGHT")
+	draftAddCmd.Flags().StringVar(&side, "side", re.SideRight, "side of the diff to comment on: LEFT or RIGHT")
+	draftSubmitCmd.Flags().StringVarP(&event, "event", "e", "COMMENT", "review event: APPROVE, COMMENT or REQUEST_CHANGES")
+
+	for _, cmd := range []*cobra.Command{listCmd, showCmd, diffCmd, inboxCmd} {
+		cmd.Flags().BoolVar(&offline, "offline", false, "read from the data stored by re sync instead of GitHub")
+	}
+	for _, cmd := range []*cobra.Command{commentCmd, reviewCommentCmd} {
+		cmd.Flags().BoolVar(&offline, "offline", false, "queue the comment to be submitted on the next re sync")
+	}
 
+	checkoutCmd.Flags().BoolVar(&checkoutOptions.Worktree, "worktree", false, "check out into a new worktree next to the repository")
+	worktreeCmd.AddCommand(worktreePruneCmd)
+	stackCmd.AddCommand(stackSubmitCmd)
+	stackCmd.AddCommand(stackRestackCmd)
+
+	logsCmd.Flags().StringVar(&check, "check", "", "name of the check to print the log of")
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "wait for the check to complete")
+
+	rerunCmd.Flags().BoolVar(&failedOnly, "failed-only", false, "only re-run the failed jobs of a workflow run")
+
+	authLoginCmd.Flags().BoolVar(&withToken, "with-token", false, "read the token from standard input")
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authStatusCmd)
+
+	watchCmd.Flags().DurationVar(&watchOptions.Interval, "interval", 10*time.Second, "time between polls")
+	watchCmd.Flags().DurationVar(&watchOptions.Timeout, "timeout", 30*time.Minute, "give up after this long, 0 waits indefinitely")
+	watchCmd.Flags().StringVar(&watchOptions.Exec, "exec", "", "shell command to run when done, RE_WATCH_RESULT is set to success, failure or timeout")
+	watchCmd.Flags().BoolVar(&watchOptions.Bell, "bell", false, "ring the terminal bell when done")
+
+	mergeCmd.Flags().BoolVar(&squash, "squash", false, "squash the commits into one commit")
+	mergeCmd.Flags().BoolVar(&rebase, "rebase", false, "rebase the commits onto the base branch")
+	mergeCmd.Flags().BoolVar(&mergeCommit, "merge", false, "merge the commits with a merge commit (default)")
+	mergeCmd.Flags().BoolVar(&autoMerge, "auto", false, "merge automatically once all requirements are met")
+	mergeCmd.Flags().BoolVar(&deleteBranch, "delete-branch", false, "delete the head branch after merging")
+	mergeCmd.MarkFlagsMutuallyExclusive("squash", "rebase", "merge")
+
+	draftCmd.AddCommand(draftAddCmd)
+	draftCmd.AddCommand(draftListCmd)
+	draftCmd.AddCommand(draftEditCmd)
+	draftCmd.AddCommand(draftRemoveCmd)
+	draftCmd.AddCommand(draftSubmitCmd)
+	draftCmd.AddCommand(draftDiscardCmd)
+
+	rootCmd.AddCommand(authCmd)
 	rootCmd.AddCommand(readyCmd)
+	rootCmd.AddCommand(requestChangesCmd)
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(commentCmd)
 	rootCmd.AddCommand(checkoutCmd)
+	rootCmd.AddCommand(checksCmd)
 	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(dismissCmd)
+	rootCmd.AddCommand(draftCmd)
 	rootCmd.AddCommand(approveCmd)
 	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(reviewCommentCmd)
+	rootCmd.AddCommand(replyCmd)
+	rootCmd.AddCommand(rerunCmd)
+	rootCmd.AddCommand(resolveCmd)
+	rootCmd.AddCommand(unresolveCmd)
 	rootCmd.AddCommand(openCmd)
 	rootCmd.AddCommand(inboxCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(mergeCmd)
 	rootCmd.AddCommand(pushCmd)
 	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(stackCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(tuiCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(worktreeCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		exit(err)
@@ -188,5 +683,23 @@ func main() {
 
 func exit(err error) {
 	fmt.Fprintln(os.Stderr, err)
-	os.Exit(1)
+	var apiErr *re.APIError
+	if errors.As(err, &apiErr) {
+		if hint := apiErr.Hint(); hint != "" {
+			fmt.Fprintln(os.Stderr, "hint:", hint)
+		}
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the status to exit with for an error, which re watch
+// documents to tell failed checks apart from a timeout.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, re.ErrChecksFailed):
+		return 2
+	case errors.Is(err, re.ErrWatchTimeout):
+		return 3
+	}
+	return 1
 }
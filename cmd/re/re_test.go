@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	re "github.com/konradreiche/re/internal"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: errors.New("boom"), want: 1},
+		{err: fmt.Errorf("#1: %w", re.ErrChecksFailed), want: 2},
+		{err: fmt.Errorf("#1: %w", re.ErrWatchTimeout), want: 3},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package re
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	gqlclient "git.sr.ht/~emersion/gqlclient"
+)
+
+// APIError is an error returned by the GitHub REST or GraphQL API.
+type APIError struct {
+	// StatusCode is the HTTP status of the response. GraphQL errors are
+	// usually reported with 200 OK.
+	StatusCode int
+	Message    string
+	// DocumentationURL links to the documentation of the endpoint.
+	DocumentationURL string
+	// Errors lists the fields that failed validation.
+	Errors []FieldError
+	// SSOURL is the URL to authorize the token for an organization enforcing
+	// SAML single sign-on.
+	SSOURL string
+	// AcceptedScopes are the scopes the endpoint accepts, Scopes those the
+	// token has been granted.
+	AcceptedScopes []string
+	Scopes         []string
+
+	err error
+}
+
+// FieldError describes why a field of a request failed validation.
+type FieldError struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	field := e.Field
+	if e.Resource != "" {
+		field = e.Resource + "." + field
+	}
+	return fmt.Sprintf("%s %s", field, strings.ReplaceAll(e.Code, "_", " "))
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString("GitHub API")
+	if e.StatusCode != 0 && e.StatusCode != http.StatusOK {
+		fmt.Fprintf(&b, " %d", e.StatusCode)
+	}
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	b.WriteString(": " + message)
+	if len(e.Errors) > 0 {
+		fields := make([]string, len(e.Errors))
+		for i, field := range e.Errors {
+			fields[i] = field.String()
+		}
+		b.WriteString(" (" + strings.Join(fields, ", ") + ")")
+	}
+	return b.String()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// missingScopesPattern matches the scopes GraphQL reports as required, such as
+// "requires one of the following scopes: ['repo', 'read:org']".
+var missingScopesPattern = regexp.MustCompile(`requires one of the following scopes: \[([^\]]*)\]`)
+
+// Hint suggests how to resolve the error, or returns an empty string if there
+// is nothing more to say than the message.
+func (e *APIError) Hint() string {
+	if e.SSOURL != "" {
+		return "authorize the token for SSO at " + e.SSOURL
+	}
+	if strings.Contains(e.Message, "SAML enforcement") {
+		return "authorize the token for SSO in the settings of the token"
+	}
+	if e.StatusCode == http.StatusUnauthorized {
+		return "the token is invalid or expired, run re auth login"
+	}
+	if missing := e.missingScopes(); len(missing) > 0 {
+		quoted := make([]string, len(missing))
+		for i, scope := range missing {
+			quoted[i] = "`" + scope + "`"
+		}
+		scope := "scope"
+		if len(missing) > 1 {
+			scope = "scopes"
+		}
+		return fmt.Sprintf("token lacks %s %s, grant it in the settings of the token or run re auth login",
+			strings.Join(quoted, " or "), scope)
+	}
+	if e.StatusCode == http.StatusNotFound && len(e.Scopes) == 0 {
+		// Private repositories are reported as missing to tokens without
+		// access to them.
+		return "the repository may be private, check that the token has access to it"
+	}
+	if e.DocumentationURL != "" {
+		return "see " + e.DocumentationURL
+	}
+	return ""
+}
+
+// missingScopes returns the scopes the endpoint accepts of which the token has
+// none.
+func (e *APIError) missingScopes() []string {
+	accepted := e.AcceptedScopes
+	if m := missingScopesPattern.FindStringSubmatch(e.Message); m != nil {
+		accepted = nil
+		for _, scope := range strings.Split(m[1], ",") {
+			accepted = append(accepted, strings.Trim(strings.TrimSpace(scope), `'"`))
+		}
+	} else if e.StatusCode != http.StatusForbidden && e.StatusCode != http.StatusNotFound {
+		return nil
+	}
+	for _, scope := range accepted {
+		for _, granted := range e.Scopes {
+			if scope == granted {
+				return nil
+			}
+		}
+	}
+	return accepted
+}
+
+// newAPIError builds an [APIError] from an unsuccessful REST response. The body
+// is consumed.
+func newAPIError(resp *http.Response) error {
+	apiErr := &APIError{
+		StatusCode:     resp.StatusCode,
+		AcceptedScopes: splitHeader(resp.Header.Get("X-Accepted-OAuth-Scopes")),
+		Scopes:         splitHeader(resp.Header.Get("X-OAuth-Scopes")),
+	}
+	// The header is set to "required; url=<url>" if the organization enforces
+	// SSO and the token is not authorized for it.
+	if sso := resp.Header.Get("X-GitHub-SSO"); sso != "" {
+		for _, part := range strings.Split(sso, ";") {
+			if url, ok := strings.CutPrefix(strings.TrimSpace(part), "url="); ok {
+				apiErr.SSOURL = url
+			}
+		}
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Join(apiErr, err)
+	}
+	var body struct {
+		Message          string       `json:"message"`
+		DocumentationURL string       `json:"documentation_url"`
+		Errors           []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		// Not every error comes from the API itself, such as those of proxies.
+		apiErr.Message = strings.TrimSpace(string(b))
+		return apiErr
+	}
+	apiErr.Message = body.Message
+	apiErr.DocumentationURL = body.DocumentationURL
+	apiErr.Errors = body.Errors
+	return apiErr
+}
+
+func splitHeader(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// graphQLError converts the errors returned by the GraphQL client into an
+// [APIError], keeping the original error available through errors.As. Other
+// errors, such as network failures, are returned unchanged.
+func graphQLError(err error) error {
+	if err == nil {
+		return nil
+	}
+	apiErr := &APIError{StatusCode: http.StatusOK, err: err}
+	var httpErr *gqlclient.HTTPError
+	if errors.As(err, &httpErr) {
+		apiErr.StatusCode = httpErr.StatusCode
+	}
+	var messages []string
+	for _, gqlErr := range gqlErrors(err) {
+		messages = append(messages, gqlErr.Message)
+	}
+	if httpErr == nil && len(messages) == 0 {
+		return err
+	}
+	apiErr.Message = strings.Join(messages, "; ")
+	return apiErr
+}
+
+// gqlErrors returns the GraphQL errors contained in err, which the client
+// joins together.
+func gqlErrors(err error) []*gqlclient.Error {
+	switch err := err.(type) {
+	case *gqlclient.Error:
+		return []*gqlclient.Error{err}
+	case interface{ Unwrap() []error }:
+		var errs []*gqlclient.Error
+		for _, err := range err.Unwrap() {
+			errs = append(errs, gqlErrors(err)...)
+		}
+		return errs
+	case interface{ Unwrap() error }:
+		return gqlErrors(err.Unwrap())
+	}
+	return nil
+}
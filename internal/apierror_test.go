@@ -0,0 +1,129 @@
+package re
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	gqlclient "git.sr.ht/~emersion/gqlclient"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		body     string
+		want     *APIError
+		wantHint string
+	}{
+		{
+			name:   "validation",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"message":"Validation Failed","errors":[{"resource":"PullRequest","field":"base","code":"invalid"}],"documentation_url":"https://docs.github.com/rest"}`,
+			want: &APIError{
+				StatusCode:       http.StatusUnprocessableEntity,
+				Message:          "Validation Failed",
+				DocumentationURL: "https://docs.github.com/rest",
+				Errors:           []FieldError{{Resource: "PullRequest", Field: "base", Code: "invalid"}},
+			},
+			wantHint: "see https://docs.github.com/rest",
+		},
+		{
+			name:   "missing-scope",
+			status: http.StatusNotFound,
+			header: http.Header{
+				"X-Accepted-Oauth-Scopes": {"repo"},
+				"X-Oauth-Scopes":          {"read:org, gist"},
+			},
+			body: `{"message":"Not Found"}`,
+			want: &APIError{
+				StatusCode:     http.StatusNotFound,
+				Message:        "Not Found",
+				AcceptedScopes: []string{"repo"},
+				Scopes:         []string{"read:org", "gist"},
+			},
+			wantHint: "token lacks `repo` scope, grant it in the settings of the token or run re auth login",
+		},
+		{
+			name:   "sso",
+			status: http.StatusForbidden,
+			header: http.Header{
+				"X-Github-Sso": {"required; url=https://github.com/orgs/acme/sso?authorization_request=1"},
+			},
+			body: `{"message":"Resource protected by organization SAML enforcement."}`,
+			want: &APIError{
+				StatusCode: http.StatusForbidden,
+				Message:    "Resource protected by organization SAML enforcement.",
+				SSOURL:     "https://github.com/orgs/acme/sso?authorization_request=1",
+			},
+			wantHint: "authorize the token for SSO at https://github.com/orgs/acme/sso?authorization_request=1",
+		},
+		{
+			name:   "not-json",
+			status: http.StatusBadGateway,
+			body:   "bad gateway\n",
+			want: &APIError{
+				StatusCode: http.StatusBadGateway,
+				Message:    "bad gateway",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			err := newAPIError(&http.Response{
+				StatusCode: tt.status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %T, want *APIError", err)
+			}
+			if diff := cmp.Diff(tt.want, apiErr, cmpopts.IgnoreUnexported(APIError{})); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantHint, apiErr.Hint()); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestGraphQLError(t *testing.T) {
+	gqlErr := errors.Join(
+		&gqlclient.Error{Message: "Your token has not been granted the required scopes to execute this query. The 'login' field requires one of the following scopes: ['read:org'], but your token has only been granted the: ['repo'] scopes."},
+		&gqlclient.Error{Message: "Something else"},
+	)
+	err := fmt.Errorf("FetchLogin: %w", graphQLError(gqlErr))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T, want *APIError", err)
+	}
+	if diff := cmp.Diff(http.StatusOK, apiErr.StatusCode); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	want := "token lacks `read:org` scope, grant it in the settings of the token or run re auth login"
+	if diff := cmp.Diff(want, apiErr.Hint()); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	var original *gqlclient.Error
+	if !errors.As(err, &original) {
+		t.Error("original GraphQL error is not preserved")
+	}
+
+	networkErr := errors.New("HTTP request failed: connection refused")
+	if got := graphQLError(networkErr); got != networkErr {
+		t.Errorf("got %v, want the error unchanged", got)
+	}
+}
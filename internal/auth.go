@@ -0,0 +1,186 @@
+package re
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AuthStatus describes the access token used for a GitHub instance.
+type AuthStatus struct {
+	Host   string   `json:"host"`
+	Login  string   `json:"login"`
+	Source string   `json:"source"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is zero for tokens without expiration.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// FetchAuthStatus verifies the access token of the configuration and returns
+// the user it belongs to, along with the scopes and expiration reported in the
+// response headers.
+func FetchAuthStatus(ctx context.Context, config Config) (*AuthStatus, error) {
+	if config.AccessToken == "" {
+		return nil, fmt.Errorf("not logged in to %s, run re auth login", config.Host)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.RESTEndpoint+"/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: &authenticatedTransport{
+			transport:   http.DefaultTransport,
+			accessToken: config.AccessToken,
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("token for %s from %s is invalid or expired", config.Host, config.TokenSource)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, err
+	}
+	status := &AuthStatus{
+		Host:   config.Host,
+		Login:  user.Login,
+		Source: config.TokenSource,
+	}
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			status.Scopes = append(status.Scopes, scope)
+		}
+	}
+	// Fine-grained and expiring classic tokens report their expiration, such
+	// as "2024-05-01 10:00:00 UTC".
+	if expiration := resp.Header.Get("GitHub-Authentication-Token-Expiration"); expiration != "" {
+		expiresAt, err := time.Parse("2006-01-02 15:04:05 MST", expiration)
+		if err != nil {
+			return nil, fmt.Errorf("FetchAuthStatus: %w", err)
+		}
+		status.ExpiresAt = expiresAt
+	}
+	return status, nil
+}
+
+// Login verifies the token and stores it for the host of the configuration.
+func Login(ctx context.Context, config Config, store CredentialStore, token string) (*AuthStatus, error) {
+	config.AccessToken = strings.TrimSpace(token)
+	config.TokenSource = store.Name()
+	status, err := FetchAuthStatus(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Set(config.Host, config.AccessToken); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Logout removes the stored token for the host of the configuration.
+func Logout(config Config, store CredentialStore) error {
+	if err := store.Delete(config.Host); err != nil {
+		if errors.Is(err, ErrNoCredentials) {
+			return fmt.Errorf("not logged in to %s", config.Host)
+		}
+		return err
+	}
+	return nil
+}
+
+// deviceCode is the response of the first step of the OAuth device flow.
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// deviceFlowScopes are the scopes requested when logging in with the device
+// flow.
+const deviceFlowScopes = "repo read:org notifications workflow"
+
+// AuthorizeDevice obtains a token through the OAuth device flow. It prints the
+// code the user has to enter in the browser to w and polls until the user
+// authorized the app.
+func AuthorizeDevice(ctx context.Context, config Config, w io.Writer) (string, error) {
+	if config.OAuthClientID == "" {
+		return "", fmt.Errorf("no oauth_client_id configured for %s", config.Host)
+	}
+	var code deviceCode
+	err := postForm(ctx, config.WebURL()+"/login/device/code", url.Values{
+		"client_id": {config.OAuthClientID},
+		"scope":     {deviceFlowScopes},
+	}, &code)
+	if err != nil {
+		return "", fmt.Errorf("AuthorizeDevice: %w", err)
+	}
+	fmt.Fprintf(w, "Enter the code %s at %s\n", code.UserCode, code.VerificationURI)
+
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+		var token struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		err := postForm(ctx, config.WebURL()+"/login/oauth/access_token", url.Values{
+			"client_id":   {config.OAuthClientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &token)
+		if err != nil {
+			return "", fmt.Errorf("AuthorizeDevice: %w", err)
+		}
+		switch token.Error {
+		case "":
+			return token.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return "", fmt.Errorf("AuthorizeDevice: %s", token.Description)
+		}
+	}
+	return "", errors.New("AuthorizeDevice: code expired")
+}
+
+func postForm(ctx context.Context, endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
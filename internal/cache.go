@@ -0,0 +1,241 @@
+package re
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// graphQLCacheTTL is how long GraphQL query results are reused. GraphQL does
+// not support conditional requests, so results are only kept briefly to serve
+// commands run in quick succession.
+const graphQLCacheTTL = time.Minute
+
+// cacheMaxAge is how long cached responses are kept without being used.
+const cacheMaxAge = 30 * 24 * time.Hour
+
+type noCacheKey struct{}
+
+// withoutCache returns a context whose GraphQL queries bypass the cache, for
+// results that must be current, such as the checks of a pull request that is
+// being watched or merged.
+func withoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// cacheTransport caches API responses on disk. REST responses are stored with
+// their ETag or Last-Modified header and revalidated with conditional
+// requests, which do not count against the rate limit if the response did not
+// change. GraphQL query results are reused for a short time instead.
+type cacheTransport struct {
+	transport http.RoundTripper
+	dir       string
+	// scope separates the entries of different tokens, which may not have
+	// access to the same resources.
+	scope  string
+	ttl    time.Duration
+	maxAge time.Duration
+	now    func() time.Time
+	evict  sync.Once
+}
+
+func newCacheTransport(transport http.RoundTripper, dir, token string) *cacheTransport {
+	sum := sha256.Sum256([]byte(token))
+	return &cacheTransport{
+		transport: transport,
+		dir:       dir,
+		scope:     hex.EncodeToString(sum[:8]),
+		ttl:       graphQLCacheTTL,
+		maxAge:    cacheMaxAge,
+		now:       time.Now,
+	}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.evict.Do(t.evictStale)
+	switch {
+	case req.Method == http.MethodGet:
+		return t.conditional(req)
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/graphql"):
+		return t.graphQL(req)
+	}
+	// Anything else changes state, which cached query results may no longer
+	// reflect.
+	t.invalidate()
+	return t.transport.RoundTrip(req)
+}
+
+// conditional serves a REST request, revalidating the cached response if
+// there is one.
+func (t *cacheTransport) conditional(req *http.Request) (*http.Response, error) {
+	path := t.path("rest", req.URL.String())
+	cached, _ := t.load(path, req)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		// Mark the entry as used, so that it is not evicted.
+		now := t.now()
+		_ = os.Chtimes(path, now, now)
+		return cached, nil
+	}
+	if cached != nil {
+		cached.Body.Close()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return resp, nil
+	}
+	return t.store(path, resp)
+}
+
+// graphQL serves a GraphQL request, reusing the result of the same query with
+// the same variables if it is recent enough. Mutations are never cached.
+func (t *cacheTransport) graphQL(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || isMutation(payload.Query) {
+		t.invalidate()
+		return t.transport.RoundTrip(req)
+	}
+	if req.Context().Value(noCacheKey{}) != nil {
+		return t.transport.RoundTrip(req)
+	}
+
+	path := t.path("graphql", req.URL.String()+"\n"+string(body))
+	if info, err := os.Stat(path); err == nil && t.now().Sub(info.ModTime()) < t.ttl {
+		if cached, err := t.load(path, req); err == nil {
+			return cached, nil
+		}
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	// Errors may be transient or specific to the moment, such as a missing
+	// pull request that is about to be created.
+	if bytes.Contains(b, []byte(`"errors":`)) {
+		return resp, nil
+	}
+	return t.store(path, resp)
+}
+
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+// readRequestBody reads the body of a request and restores it, so that it
+// can be sent and, if needed, retried.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return b, nil
+}
+
+func (t *cacheTransport) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(t.dir, t.scope, kind, hex.EncodeToString(sum[:]))
+}
+
+func (t *cacheTransport) load(path string, req *http.Request) (*http.Response, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+}
+
+// store writes the response to the cache and returns it with its body
+// restored.
+func (t *cacheTransport) store(path string, resp *http.Response) (*http.Response, error) {
+	b, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to cache a response does not fail the request.
+	_ = writeFileAtomic(path, b)
+	return resp, nil
+}
+
+// invalidate removes the cached GraphQL results, since a request changing
+// state, such as submitting a review, makes them outdated. REST responses are
+// revalidated anyway.
+func (t *cacheTransport) invalidate() {
+	// Failing to remove them is not worth failing the request over, they
+	// expire shortly anyway.
+	_ = os.RemoveAll(filepath.Join(t.dir, t.scope, "graphql"))
+}
+
+// evictStale removes the cached responses of all tokens that were not used
+// for longer than maxAge, so that the cache does not grow without bound.
+func (t *cacheTransport) evictStale() {
+	paths, err := filepath.Glob(filepath.Join(t.dir, "*", "*", "*"))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && t.now().Sub(info.ModTime()) > t.maxAge {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// cacheDir returns the directory API responses are cached in.
+func cacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "re", "http"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "re", "http"), nil
+}
@@ -0,0 +1,151 @@
+package re
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCacheTransportConditional(t *testing.T) {
+	var ifNoneMatch []string
+	transport := newCacheTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		ifNoneMatch = append(ifNoneMatch, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{
+				StatusCode: http.StatusNotModified,
+				Header:     http.Header{"Etag": {`"v1"`}},
+				Body:       http.NoBody,
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Etag": {`"v1"`}},
+			Body:       io.NopCloser(strings.NewReader(`[{"filename":"main.go"}]`)),
+		}, nil
+	}), t.TempDir(), "token")
+
+	var bodies []string
+	for range 2 {
+		req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/pulls/1/files", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		bodies = append(bodies, string(b))
+	}
+
+	if diff := cmp.Diff([]string{"", `"v1"`}, ifNoneMatch); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	want := []string{`[{"filename":"main.go"}]`, `[{"filename":"main.go"}]`}
+	if diff := cmp.Diff(want, bodies); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestCacheTransportGraphQL(t *testing.T) {
+	requests := 0
+	transport := newCacheTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"data":{"viewer":{"login":"octocat"}}}`)),
+		}, nil
+	}), t.TempDir(), "token")
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+
+	post := func(query string) string {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", strings.NewReader(`{"query":"`+query+`"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	want := `{"data":{"viewer":{"login":"octocat"}}}`
+	for _, step := range []struct {
+		name         string
+		query        string
+		advance      time.Duration
+		wantRequests int
+	}{
+		{name: "miss", query: "query fetchLogin { viewer { login } }", wantRequests: 1},
+		{name: "hit", query: "query fetchLogin { viewer { login } }", wantRequests: 1},
+		{name: "other-query", query: "query other { viewer { login } }", wantRequests: 2},
+		{name: "expired", query: "query fetchLogin { viewer { login } }", advance: 2 * graphQLCacheTTL, wantRequests: 3},
+		{name: "mutation", query: "mutation markAsReady { x }", wantRequests: 4},
+		{name: "invalidated", query: "query fetchLogin { viewer { login } }", wantRequests: 5},
+	} {
+		now = now.Add(step.advance)
+		if diff := cmp.Diff(want, post(step.query)); diff != "" {
+			t.Errorf("%s: diff: %s", step.name, diff)
+		}
+		if requests != step.wantRequests {
+			t.Errorf("%s: got %d requests, want %d", step.name, requests, step.wantRequests)
+		}
+	}
+}
+
+func TestCacheTransportEvictStale(t *testing.T) {
+	transport := newCacheTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), t.TempDir(), "token")
+	stale := transport.path("rest", "https://api.github.com/repos/o/r/pulls/1/files")
+	recent := transport.path("rest", "https://api.github.com/repos/o/r/pulls/2/files")
+	for _, path := range []string{stale, recent} {
+		if err := writeFileAtomic(path, []byte("HTTP/1.1 200 OK\r\n\r\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * cacheMaxAge)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if _, err := os.Stat(stale); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got %v for the stale entry, want it to be evicted", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("got %v for the recent entry, want it to be kept", err)
+	}
+}
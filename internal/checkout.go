@@ -0,0 +1,282 @@
+package re
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// pullRequestConfigKey is the branch setting that records the pull request a
+// branch was checked out for, so that its worktree can be pruned once the pull
+// request is merged.
+const pullRequestConfigKey = "re-pull-request"
+
+// PullRequestHead describes the branch a pull request merges.
+type PullRequestHead struct {
+	Number int
+	State  PullRequestState
+	// Ref is the name of the head branch.
+	Ref string
+	// Owner and Name are those of the head repository, which is a fork for
+	// cross-repository pull requests. They are empty if the fork was deleted.
+	Owner  string
+	Name   string
+	URL    string
+	SSHURL string
+	// IsCrossRepository is set if the head branch is in a fork.
+	IsCrossRepository bool
+	// MaintainerCanModify is set if maintainers of the base repository may
+	// push to the head branch of the fork.
+	MaintainerCanModify bool
+}
+
+// FetchPullRequestHead returns the head branch of a pull request.
+func (c *Client) FetchPullRequestHead(ctx context.Context, owner, name string, number int) (*PullRequestHead, error) {
+	rateLimit, repository, err := FetchPullRequestHead(c.gql, ctx, int32(number), owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("FetchPullRequestHead: %w", graphQLError(err))
+	}
+	c.transport.recordCost(rateLimit)
+	pr := repository.PullRequest
+	head := &PullRequestHead{
+		Number:              int(pr.Number),
+		State:               pr.State,
+		Ref:                 pr.HeadRefName,
+		IsCrossRepository:   pr.IsCrossRepository,
+		MaintainerCanModify: pr.MaintainerCanModify,
+	}
+	if repo := pr.HeadRepository; repo != nil {
+		head.Name = repo.Name
+		head.URL = string(repo.Url)
+		head.SSHURL = string(repo.SshUrl)
+		if repo.Owner != nil {
+			head.Owner = repo.Owner.Login
+		}
+	}
+	return head, nil
+}
+
+// CheckoutOptions configures how a pull request is checked out.
+type CheckoutOptions struct {
+	// Worktree checks the pull request out into its own worktree next to
+	// the main worktree instead of switching branches.
+	Worktree bool
+}
+
+// CheckoutPullRequest checks out a pull request into a local branch named
+// after its head branch, which tracks the head branch so that commits can be
+// pulled and pushed. The branch of a fork is tracked through a remote for the
+// fork if maintainers can modify it, and the read-only pull request ref of
+// the base repository otherwise.
+func (c *Command) CheckoutPullRequest(ctx context.Context, pr int, opts CheckoutOptions) error {
+	head, err := c.client.FetchPullRequestHead(ctx, c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	remote, ref := c.base.RemoteName, "refs/heads/"+head.Ref
+	if head.IsCrossRepository {
+		if head.MaintainerCanModify && head.Owner != "" {
+			remote, err = c.forkRemote(ctx, head)
+			if err != nil {
+				return err
+			}
+		} else {
+			ref = fmt.Sprintf("refs/pull/%d/head", pr)
+		}
+	}
+	commit, err := c.git.FetchRef(ctx, remote, ref)
+	if err != nil {
+		return err
+	}
+
+	branch := c.checkoutBranch(ctx, head, remote, ref)
+	exists := BranchExists(ctx, c.git, branch)
+	var behind bool
+	if exists {
+		// Local commits that were not pushed yet are kept, the branch is left
+		// as it is then. It is only fast-forwarded if it is behind.
+		ahead, err := c.git.IsAncestor(ctx, commit, branch)
+		if err != nil {
+			return err
+		}
+		if !ahead {
+			behind, err = c.git.IsAncestor(ctx, branch, commit)
+			if err != nil {
+				return err
+			}
+			if !behind {
+				return fmt.Errorf("CheckoutPullRequest: %s has diverged from #%d", branch, pr)
+			}
+		}
+	}
+	if opts.Worktree {
+		path, err := c.checkoutWorktree(ctx, pr, branch, commit, exists, behind)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Checked out #%d into branch %s in %s\n", pr, branch, path)
+	} else {
+		if exists {
+			err = c.git.Switch(ctx, branch, "")
+			if err == nil && behind {
+				err = c.git.Merge(ctx, commit)
+			}
+		} else {
+			err = c.git.Switch(ctx, branch, commit)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Checked out #%d into branch %s\n", pr, branch)
+	}
+	if err := SetBranchUpstream(ctx, c.git, branch, remote, ref); err != nil {
+		return err
+	}
+	return c.git.SetConfig(ctx, "branch."+branch+"."+pullRequestConfigKey, strconv.Itoa(pr))
+}
+
+// checkoutBranch returns the name of the local branch for a pull request. It
+// is the name of the head branch, unless a local branch of that name already
+// tracks something else, such as main of a fork when main of the base
+// repository is checked out. The owner of the head repository is prepended
+// then.
+func (c *Command) checkoutBranch(ctx context.Context, head *PullRequestHead, remote, ref string) string {
+	if !BranchExists(ctx, c.git, head.Ref) {
+		return head.Ref
+	}
+	if trackedRemote, trackedRef := BranchUpstream(ctx, c.git, head.Ref); trackedRemote == remote && trackedRef == ref {
+		return head.Ref
+	}
+	owner := head.Owner
+	if owner == "" {
+		owner = "pr-" + strconv.Itoa(head.Number)
+	}
+	return owner + "/" + head.Ref
+}
+
+// forkRemote returns the remote of the head repository of a pull request,
+// adding a remote named after the owner of the fork if there is none yet. Its
+// URL uses the same protocol as the remote of the base repository.
+func (c *Command) forkRemote(ctx context.Context, head *PullRequestHead) (string, error) {
+	fork := Remote{Host: c.base.Host, Owner: head.Owner, Name: head.Name}
+	names, err := c.git.Remotes(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		remote, err := LookupRemote(ctx, c.git, name)
+		if err == nil && remote.SameRepository(fork) {
+			return name, nil
+		}
+	}
+	for _, name := range names {
+		if name == head.Owner {
+			return "", fmt.Errorf("forkRemote: remote %s exists but does not point to %s/%s", name, head.Owner, head.Name)
+		}
+	}
+	baseURL, err := c.git.RemoteURL(ctx, c.base.RemoteName)
+	if err != nil {
+		return "", err
+	}
+	url := head.SSHURL
+	if strings.HasPrefix(baseURL, "https://") || strings.HasPrefix(baseURL, "http://") {
+		url = head.URL + ".git"
+	}
+	if err := c.git.AddRemote(ctx, head.Owner, url); err != nil {
+		return "", err
+	}
+	return head.Owner, nil
+}
+
+// checkoutWorktree checks a branch out into a worktree next to the main
+// worktree, named after the repository and the pull request. A branch that is
+// already checked out in a worktree is left there, and reported if it is
+// behind, since only that worktree can update it.
+func (c *Command) checkoutWorktree(ctx context.Context, pr int, branch, commit string, exists, behind bool) (string, error) {
+	worktrees, err := c.git.Worktrees(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(worktrees) == 0 {
+		return "", errors.New("checkoutWorktree: no main worktree")
+	}
+	for _, worktree := range worktrees {
+		if worktree.Branch == branch {
+			if behind {
+				fmt.Fprintf(c.out, "Branch %s in %s is behind #%d, run git pull --ff-only there to update it\n", branch, worktree.Path, pr)
+			}
+			return worktree.Path, nil
+		}
+	}
+	main := worktrees[0].Path
+	path := filepath.Join(filepath.Dir(main), fmt.Sprintf("%s-pr-%d", filepath.Base(main), pr))
+	if !exists {
+		return path, c.git.AddWorktree(ctx, path, branch, commit)
+	}
+	// The branch is not checked out anywhere, so it can be fast-forwarded
+	// directly.
+	if behind {
+		if err := c.git.SetBranch(ctx, branch, commit); err != nil {
+			return "", err
+		}
+	}
+	return path, c.git.AddWorktree(ctx, path, branch, "")
+}
+
+// PruneWorktrees removes the worktrees of merged pull requests along with
+// their branches. Worktrees with uncommitted changes are kept, as are those
+// whose branch has commits the pull request does not.
+func (c *Command) PruneWorktrees(ctx context.Context) error {
+	worktrees, err := c.git.Worktrees(ctx)
+	if err != nil {
+		return err
+	}
+	// The main worktree cannot be removed.
+	for _, worktree := range worktrees[min(1, len(worktrees)):] {
+		if worktree.Branch == "" {
+			continue
+		}
+		value, err := c.git.Config(ctx, "branch."+worktree.Branch+"."+pullRequestConfigKey)
+		if err != nil {
+			return err
+		}
+		pr, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		head, err := c.client.FetchPullRequestHead(ctx, c.org, c.name, pr)
+		if err != nil {
+			return err
+		}
+		if head.State != PullRequestStateMerged {
+			continue
+		}
+		// The branch is deleted along with the worktree, which would lose
+		// commits made after the pull request was merged. The pull request
+		// ref is used since the head branch is usually deleted by then.
+		commit, err := c.git.FetchRef(ctx, c.base.RemoteName, fmt.Sprintf("refs/pull/%d/head", pr))
+		if err != nil {
+			return err
+		}
+		pushed, err := c.git.IsAncestor(ctx, worktree.Branch, commit)
+		if err != nil {
+			return err
+		}
+		if !pushed {
+			fmt.Fprintf(c.out, "Kept %s of merged #%d: %s has commits that are not part of the pull request\n", worktree.Path, pr, worktree.Branch)
+			continue
+		}
+		if err := c.git.RemoveWorktree(ctx, worktree.Path); err != nil {
+			fmt.Fprintf(c.out, "Kept %s of merged #%d: %v\n", worktree.Path, pr, err)
+			continue
+		}
+		if err := c.git.DeleteBranch(ctx, worktree.Branch); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Removed %s of merged #%d\n", worktree.Path, pr)
+	}
+	return c.git.PruneWorktrees(ctx)
+}
@@ -0,0 +1,171 @@
+package re
+
+import (
+	"time"
+)
+
+// Check is a single CI check of the head commit of a pull request. It is
+// either a check run, such as a GitHub Actions job, or a commit status
+// reported through the legacy Status API.
+type Check struct {
+	// ID is the database ID of the check run, zero for commit statuses.
+	ID int `json:"id,omitempty"`
+	// SuiteID is the database ID of the check suite the check run belongs to.
+	SuiteID int `json:"suiteId,omitempty"`
+	// RunID is the database ID of the GitHub Actions workflow run, if the
+	// check suite was created by one.
+	RunID    int    `json:"runId,omitempty"`
+	Name     string `json:"name"`
+	Workflow string `json:"workflow,omitempty"`
+	// State is the conclusion of a completed check run, the status of a check
+	// run in progress, or the state of a commit status.
+	State       string    `json:"state"`
+	Description string    `json:"description,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	URL         string    `json:"url,omitempty"`
+}
+
+// Duration returns how long the check ran, or has been running so far.
+func (c Check) Duration() time.Duration {
+	if c.StartedAt.IsZero() {
+		return 0
+	}
+	end := c.CompletedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(c.StartedAt).Round(time.Second)
+}
+
+// Status condenses the state of the check into a commit status state.
+func (c Check) Status() StatusState {
+	switch c.State {
+	case string(StatusStateSuccess), string(CheckConclusionStateNeutral), string(CheckConclusionStateSkipped):
+		return StatusStateSuccess
+	case string(StatusStateError):
+		return StatusStateError
+	case string(CheckConclusionStateFailure), string(CheckConclusionStateTimedOut),
+		string(CheckConclusionStateCancelled), string(CheckConclusionStateActionRequired),
+		string(CheckConclusionStateStartupFailure), string(CheckConclusionStateStale):
+		return StatusStateFailure
+	}
+	return StatusStatePending
+}
+
+func checkRunState(status CheckStatusState, conclusion *CheckConclusionState) string {
+	if status != CheckStatusStateCompleted || conclusion == nil {
+		return string(status)
+	}
+	return string(*conclusion)
+}
+
+// newChecks returns the check runs and commit statuses of a commit.
+func newChecks(commit *Commit) ([]Check, error) {
+	var checks []Check
+	if commit.CheckSuites != nil {
+		for _, suite := range commit.CheckSuites.Nodes {
+			if suite.CheckRuns == nil {
+				continue
+			}
+			var (
+				workflow string
+				runID    int
+			)
+			if suite.WorkflowRun != nil {
+				runID = databaseID(suite.WorkflowRun.DatabaseId)
+			}
+			if suite.WorkflowRun != nil && suite.WorkflowRun.Workflow != nil {
+				workflow = suite.WorkflowRun.Workflow.Name
+			} else if suite.App != nil {
+				workflow = suite.App.Name
+			}
+			for _, run := range suite.CheckRuns.Nodes {
+				check := Check{
+					ID:       databaseID(run.DatabaseId),
+					SuiteID:  databaseID(suite.DatabaseId),
+					RunID:    runID,
+					Name:     run.Name,
+					Workflow: workflow,
+					State:    checkRunState(run.Status, run.Conclusion),
+				}
+				if run.DetailsUrl != nil {
+					check.URL = string(*run.DetailsUrl)
+				}
+				var err error
+				if check.StartedAt, err = parseOptionalTime(run.StartedAt); err != nil {
+					return nil, err
+				}
+				if check.CompletedAt, err = parseOptionalTime(run.CompletedAt); err != nil {
+					return nil, err
+				}
+				checks = append(checks, check)
+			}
+		}
+	}
+	if commit.Status != nil {
+		for _, context := range commit.Status.Contexts {
+			check := Check{
+				Name:  context.Context,
+				State: string(context.State),
+			}
+			if context.Description != nil {
+				check.Description = *context.Description
+			}
+			if context.TargetUrl != nil {
+				check.URL = string(*context.TargetUrl)
+			}
+			createdAt, err := parseOptionalTime(&context.CreatedAt)
+			if err != nil {
+				return nil, err
+			}
+			check.StartedAt = createdAt
+			if check.Status() != StatusStatePending {
+				check.CompletedAt = createdAt
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+func parseOptionalTime(t *DateTime) (time.Time, error) {
+	if t == nil || *t == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, string(*t))
+}
+
+// statusCheck condenses the status checks of a pull request into a single
+// state. The status check rollup of the head commit already combines commit
+// statuses and check runs, such as GitHub Actions jobs, so listing pull
+// requests does not need to fetch the individual checks.
+func statusCheck(pr *PullRequest) StatusState {
+	state := StatusStateSuccess
+	if pr.Commits == nil {
+		return state
+	}
+	for _, node := range pr.Commits.Nodes {
+		// Not every commit has checks associated with it.
+		if rollup := node.Commit.StatusCheckRollup; rollup != nil {
+			state = worseStatus(state, rollup.State)
+		}
+	}
+	return state
+}
+
+func worseStatus(a, b StatusState) StatusState {
+	severity := func(s StatusState) int {
+		switch s {
+		case StatusStateFailure, StatusStateError:
+			return 2
+		case StatusStatePending, StatusStateExpected:
+			return 1
+		}
+		return 0
+	}
+	if severity(b) > severity(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,62 @@
+package re
+
+import (
+	"testing"
+)
+
+func TestStatusCheck(t *testing.T) {
+	commit := func(rollup *StatusCheckRollup) *PullRequest {
+		return &PullRequest{
+			Commits: &PullRequestCommitConnection{
+				Nodes: []*PullRequestCommit{{Commit: &Commit{StatusCheckRollup: rollup}}},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		pr   *PullRequest
+		want StatusState
+	}{
+		{
+			name: "success",
+			pr:   commit(&StatusCheckRollup{State: StatusStateSuccess}),
+			want: StatusStateSuccess,
+		},
+		{
+			name: "failure",
+			pr:   commit(&StatusCheckRollup{State: StatusStateFailure}),
+			want: StatusStateFailure,
+		},
+		{
+			name: "pending",
+			pr:   commit(&StatusCheckRollup{State: StatusStatePending}),
+			want: StatusStatePending,
+		},
+		{
+			name: "no-checks",
+			pr:   commit(nil),
+			want: StatusStateSuccess,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusCheck(tt.pr); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeChecks(t *testing.T) {
+	checks := []Check{
+		{State: "SUCCESS"},
+		{State: "SKIPPED"},
+		{State: "IN_PROGRESS"},
+		{State: string(StatusStateExpected)},
+		{State: "FAILURE"},
+	}
+	want := checkSummary{passed: 2, pending: 2, failed: 1}
+	if got := summarizeChecks(checks); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
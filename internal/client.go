@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
 	"sort"
@@ -18,45 +17,103 @@ import (
 )
 
 type Client struct {
-	login    string
-	endpoint string
-	client   *http.Client
-	gql      *gqlclient.Client
+	login     string
+	endpoint  string
+	client    *http.Client
+	gql       *gqlclient.Client
+	transport *retryTransport
 }
 
 func NewClient(ctx context.Context, config Config) (*Client, error) {
+	transport := newRetryTransport(&authenticatedTransport{
+		transport:   http.DefaultTransport,
+		accessToken: config.AccessToken,
+	})
 	client := &http.Client{
-		Transport: &authenticatedTransport{
-			transport:   http.DefaultTransport,
-			accessToken: config.AccessToken,
-		},
+		Transport: transport,
+	}
+	if config.CacheDir != "" {
+		client.Transport = newCacheTransport(transport, config.CacheDir, config.AccessToken)
 	}
 	result := &Client{
-		endpoint: config.RESTEndpoint,
-		client:   client,
-		gql:      gqlclient.New(config.Endpoint+"/graphql", client),
+		endpoint:  config.RESTEndpoint,
+		client:    client,
+		transport: transport,
+		gql:       gqlclient.New(config.Endpoint+"/graphql", client),
 	}
-	user, err := FetchLogin(result.gql, ctx)
+	rateLimit, user, err := FetchLogin(result.gql, ctx)
 	if err != nil {
-		return nil, fmt.Errorf("FetchLogin failed: %w", err)
+		return nil, fmt.Errorf("FetchLogin failed: %w", graphQLError(err))
 	}
+	result.transport.recordCost(rateLimit)
 	result.login = user.Login
 	return result, nil
 }
 
-func (c *Client) FetchPullRequests(ctx context.Context, limit int, owner, name string, closed bool) error {
+func (c *Client) FetchPullRequests(ctx context.Context, limit int, owner, name string, closed bool) ([]PullRequestSummary, error) {
 	states := []PullRequestState{"OPEN"}
 	if closed {
 		states = []PullRequestState{"CLOSED", "MERGED"}
 	}
-	repository, err := FetchPullRequests(c.gql, ctx, owner, name, int32(limit), states)
-	if err != nil {
-		return fmt.Errorf("FetchPullRequests: %w", err)
+	var (
+		edges []*PullRequestEdge
+		after *string
+	)
+	for {
+		rateLimit, repository, err := FetchPullRequests(c.gql, ctx, owner, name, pageSize(limit, len(edges)), states, after)
+		if err != nil {
+			return nil, fmt.Errorf("FetchPullRequests: %w", graphQLError(err))
+		}
+		c.transport.recordCost(rateLimit)
+		if repository == nil {
+			return nil, errors.New("FetchPullRequests: repository is nil")
+		}
+		edges = append(edges, repository.PullRequests.Edges...)
+		pageInfo := repository.PullRequests.PageInfo
+		if !hasNextPage(pageInfo, limit, len(edges)) {
+			break
+		}
+		after = pageInfo.EndCursor
+	}
+	return c.summarize(edges)
+}
+
+// maxPageSize is the maximum number of items the GitHub GraphQL API returns
+// for a single page of a connection.
+const maxPageSize = 100
+
+// pageSize returns the number of items to request for the next page, given
+// the optional cap on the total number of items. A limit of zero or less
+// fetches every item.
+func pageSize(limit, fetched int) int32 {
+	if limit <= 0 {
+		return maxPageSize
+	}
+	return int32(min(maxPageSize, limit-fetched))
+}
+
+func hasNextPage(pageInfo *PageInfo, limit, fetched int) bool {
+	if pageInfo == nil || !pageInfo.HasNextPage || pageInfo.EndCursor == nil {
+		return false
 	}
-	if repository == nil {
-		return errors.New("FetchPullRequests: repository is nil")
+	return limit <= 0 || fetched < limit
+}
+
+func (c *Client) summarize(edges []*PullRequestEdge) ([]PullRequestSummary, error) {
+	summaries := make([]PullRequestSummary, 0, len(edges))
+	for _, edge := range edges {
+		summary, err := newPullRequestSummary(c.login, edge.Node)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
 	}
-	return c.printPullRequests(repository.PullRequests.Edges)
+	return summaries, nil
+}
+
+// Login returns the login of the authenticated user.
+func (c *Client) Login() string {
+	return c.login
 }
 
 type fileResp struct {
@@ -67,34 +124,113 @@ type fileResp struct {
 	Changes          int    `json:"changes"`
 }
 
-func (c *Client) FetchDiff(ctx context.Context, owner, repository string, pullRequest int, printRaw bool) error {
-	url := c.endpoint + "/repos/" + owner + "/" + repository + "/pulls/" + fmt.Sprint(pullRequest) + "/files"
-	resp, err := c.client.Get(url)
+// FetchDiff returns the changed files of a pull request, following the pages
+// of the REST API up to the 3,000 files it lists at most.
+func (c *Client) FetchDiff(ctx context.Context, owner, repository string, pullRequest int) ([]FileDiff, error) {
+	var files []FileDiff
+	url := c.endpoint + "/repos/" + owner + "/" + repository + "/pulls/" + fmt.Sprint(pullRequest) + "/files?per_page=100"
+	for url != "" {
+		var (
+			page []fileResp
+			err  error
+		)
+		url, err = c.getPage(ctx, url, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range page {
+			files = append(files, FileDiff{
+				Filename:         file.Filename,
+				PreviousFilename: file.PreviousFilename,
+				Status:           file.Status,
+				Patch:            file.Patch,
+				Changes:          file.Changes,
+			})
+		}
+	}
+	return files, nil
+}
+
+// getPage decodes a page of a REST API listing into v and returns the URL of
+// the next page, which is empty on the last one.
+func (c *Client) getPage(ctx context.Context, url string, v any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
 	}
 	defer resp.Body.Close()
-	var result []fileResp
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&result); err != nil {
-		return err
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", err
+	}
+	return nextPageURL(resp.Header), nil
+}
+
+// nextPageURL returns the URL of the next page from a Link header such as
+// `<https://api.github.com/...&page=2>; rel="next", <...>; rel="last"`.
+func nextPageURL(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
 	}
-	return printDiff(result)
+	return ""
 }
 
 type CreatePullRequestReview struct {
-	Event string `json:"event"`
-	Body  string `json:"body,omitempty"`
+	Event    string          `json:"event"`
+	Body     string          `json:"body,omitempty"`
+	Comments []ReviewComment `json:"comments,omitempty"`
 }
 
 func (c *Client) ReviewPullRequest(ctx context.Context, owner, repository string, pullRequest int, event, comment string) error {
-	review := CreatePullRequestReview{
+	return c.SubmitReview(ctx, owner, repository, pullRequest, CreatePullRequestReview{
 		Event: event,
 		Body:  comment,
+	})
+}
+
+// CommentOnLines creates a review with comments anchored to lines of the diff.
+// Each comment is validated against the files of the pull request first, since
+// the API rejects the whole review if a single comment cannot be anchored.
+func (c *Client) CommentOnLines(ctx context.Context, owner, repository string, pullRequest int, comments ...ReviewComment) error {
+	if err := c.ValidateReviewComments(ctx, owner, repository, pullRequest, comments...); err != nil {
+		return err
 	}
+	return c.SubmitReview(ctx, owner, repository, pullRequest, CreatePullRequestReview{
+		Event:    "COMMENT",
+		Comments: comments,
+	})
+}
+
+// ValidateReviewComments verifies that every comment can be anchored to the
+// current diff of the pull request.
+func (c *Client) ValidateReviewComments(ctx context.Context, owner, repository string, pullRequest int, comments ...ReviewComment) error {
+	files, err := c.FetchDiff(ctx, owner, repository, pullRequest)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if err := validateReviewComment(comment, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) SubmitReview(ctx context.Context, owner, repository string, pullRequest int, review CreatePullRequestReview) error {
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
 	if err := encoder.Encode(review); err != nil {
@@ -106,63 +242,90 @@ func (c *Client) ReviewPullRequest(ctx context.Context, owner, repository string
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
+		return newAPIError(resp)
 	}
 	return nil
 }
 
-func (c *Client) FetchMyPullRequests(ctx context.Context, limit int) error {
-	user, err := FetchMyPullRequests(c.gql, ctx, int32(limit))
-	if err != nil {
-		return err
+func (c *Client) FetchMyPullRequests(ctx context.Context, limit int) ([]PullRequestSummary, error) {
+	var (
+		edges []*PullRequestEdge
+		after *string
+	)
+	for {
+		rateLimit, user, err := FetchMyPullRequests(c.gql, ctx, pageSize(limit, len(edges)), after)
+		if err != nil {
+			return nil, graphQLError(err)
+		}
+		c.transport.recordCost(rateLimit)
+		edges = append(edges, user.PullRequests.Edges...)
+		pageInfo := user.PullRequests.PageInfo
+		if !hasNextPage(pageInfo, limit, len(edges)) {
+			break
+		}
+		after = pageInfo.EndCursor
 	}
-	return c.printPullRequests(user.PullRequests.Edges)
+	return c.summarize(edges)
 }
 
-func (c *Client) FetchMyPullRequestReviewQueue(ctx context.Context, query, repository string, limit int) error {
-	result, err := FetchMyPullRequestReviewQueue(c.gql, ctx, query, int32(limit))
-	if err != nil {
-		return err
-	}
-	edges := make([]*PullRequestEdge, len(result.Edges))
-	for i, edge := range result.Edges {
-		pr := edge.Node.Value.(*PullRequest)
-		edges[i] = &PullRequestEdge{
-			Node: pr,
+func (c *Client) FetchMyPullRequestReviewQueue(ctx context.Context, query, repository string, limit int) ([]PullRequestSummary, error) {
+	var (
+		edges []*PullRequestEdge
+		after *string
+	)
+	for {
+		rateLimit, result, err := FetchMyPullRequestReviewQueue(c.gql, ctx, query, pageSize(limit, len(edges)), after)
+		if err != nil {
+			return nil, graphQLError(err)
+		}
+		c.transport.recordCost(rateLimit)
+		for _, edge := range result.Edges {
+			pr := edge.Node.Value.(*PullRequest)
+			edges = append(edges, &PullRequestEdge{
+				Node: pr,
+			})
+		}
+		if !hasNextPage(result.PageInfo, limit, len(edges)) {
+			break
 		}
+		after = result.PageInfo.EndCursor
 	}
-	return c.printPullRequests(edges)
+	return c.summarize(edges)
 }
 
 type Notification struct {
 	Reason  string `json:"reason"`
 	Subject struct {
 		Title            string `json:"title"`
+		Type             string `json:"type"`
 		URL              string `json:"url"`
 		LatestCommentURL string `json:"latest_comment_url"`
 	} `json:"subject"`
 	UpdatedAt string `json:"updated_at"`
 }
 
-func (c *Client) FetchNotifiations(ctx context.Context) error {
+func (c *Client) FetchNotifiations(ctx context.Context) ([]InboxItem, error) {
 	url := c.endpoint + "/notifications?participating=true&all=true"
 	resp, err := c.client.Get(url)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
+		return nil, newAPIError(resp)
 	}
 	var notifications []Notification
 	if err := json.NewDecoder(resp.Body).Decode(&notifications); err != nil {
-		return err
+		return nil, err
 	}
 
 	sort.Slice(notifications, func(i, j int) bool {
 		return notifications[i].UpdatedAt < notifications[j].UpdatedAt
 	})
 
+	var items []InboxItem
 	for _, notification := range notifications {
 		// Skip reason "review requested".
 		if notification.Reason == "review_requested" {
@@ -174,11 +337,33 @@ func (c *Client) FetchNotifiations(ctx context.Context) error {
 		if split[len(split)-2] == "commits" {
 			continue
 		}
-		name, owner, number := extractOwnerAndPR(notification.Subject.URL)
-		c.printNotificationHeader(notification, number)
-		c.FetchComments(ctx, number, name, owner, WithLast(1))
+		owner, name, number := extractOwnerAndPR(notification.Subject.URL)
+		updatedAt, err := time.Parse(time.RFC3339, notification.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		item := InboxItem{
+			Reason:     notification.Reason,
+			Title:      notification.Subject.Title,
+			Owner:      owner,
+			Repository: name,
+			Number:     number,
+			UpdatedAt:  updatedAt,
+		}
+		// Not every notification refers to a pull request with a conversation,
+		// such as issues, list them without the latest comment instead.
+		if notification.Subject.Type == "PullRequest" {
+			conversation, err := c.fetchConversation(ctx, owner, name, number)
+			if err != nil {
+				return nil, err
+			}
+			if comments := conversation.AllComments(); len(comments) > 0 {
+				item.LatestComment = comments[len(comments)-1]
+			}
+		}
+		items = append(items, item)
 	}
-	return nil
+	return items, nil
 }
 
 func extractOwnerAndPR(url string) (owner, name string, number int) {
@@ -218,7 +403,7 @@ func (c *Client) getLastReviewRequested(items []*PullRequestTimelineItems) DateT
 type CreatePullRequest struct {
 	Title          string `json:"title"`
 	Head           string `json:"head"`
-	HeadRepository string `json:"head_repo"`
+	HeadRepository string `json:"head_repo,omitempty"`
 	Base           string `json:"base"`
 	Body           string `json:"body"`
 	Draft          bool   `json:"draft"`
@@ -228,31 +413,27 @@ type CreatePullResponse struct {
 	Number int `json:"number"`
 }
 
-func (c *Client) CreatePullRequest(ctx context.Context, owner, repository string, args CreatePullRequest) error {
+func (c *Client) CreatePullRequest(ctx context.Context, owner, repository string, args CreatePullRequest) (int, error) {
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
 	if err := encoder.Encode(args); err != nil {
-		return err
+		return 0, err
 	}
 	url := c.endpoint + "/repos/" + owner + "/" + repository + "/pulls"
 	resp, err := c.client.Post(url, "application/vnd.github.v3+json", &buf)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		b, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		return errors.New(resp.Status + ": " + string(b))
+		return 0, newAPIError(resp)
 	}
 	var result CreatePullResponse
 	decoder := json.NewDecoder(resp.Body)
 	if err := decoder.Decode(&result); err != nil {
-		return err
+		return 0, err
 	}
-	fmt.Println("Created pull request", result.Number)
-	return nil
+	return result.Number, nil
 }
 
 func getAge(createdAt time.Time, align bool) string {
@@ -274,100 +455,436 @@ func getAge(createdAt time.Time, align bool) string {
 	return fmt.Sprintf(alignFormat+"m ago", int(d.Minutes()))
 }
 
-type comment struct {
-	author    string
-	body      string
-	createdAt time.Time
-	diffHunk  string
+func (c *Client) FetchDescription(ctx context.Context, number int32, name, owner string) (*Conversation, error) {
+	rateLimit, respository, err := FetchConversation(c.gql, ctx, number, name, owner)
+	if err != nil {
+		return nil, graphQLError(err)
+	}
+	c.transport.recordCost(rateLimit)
+	return newConversation(respository.PullRequest)
 }
 
-func newComment(author, body, diffHunk string, createdAt string) (*comment, error) {
-	parsed, err := time.Parse(time.RFC3339, createdAt)
+func (c *Client) FetchComments(ctx context.Context, number int, owner, name string, opts ...Option) (*Conversation, error) {
+	cfg := options{}
+	if err := WithOptions(opts...)(&cfg); err != nil {
+		return nil, err
+	}
+	conversation, err := c.fetchConversation(ctx, owner, name, number)
 	if err != nil {
 		return nil, err
 	}
-	return &comment{
-		author:    author,
-		body:      body,
-		createdAt: parsed,
-		diffHunk:  diffHunk,
-	}, nil
+	if cfg.last > 0 {
+		// Keep only the latest comments, regardless of whether they are part
+		// of a thread or not.
+		comments := conversation.AllComments()
+		if cfg.last < len(comments) {
+			comments = comments[len(comments)-cfg.last:]
+		}
+		conversation.Comments = comments
+		conversation.Threads = nil
+	}
+	return conversation, nil
 }
 
-func (c *Client) FetchDescription(ctx context.Context, number int32, name, owner string) error {
-	respository, err := FetchConversation(c.gql, ctx, number, name, owner)
+func (c *Client) fetchConversation(ctx context.Context, owner, name string, number int) (*Conversation, error) {
+	rateLimit, repository, err := FetchConversation(c.gql, ctx, int32(number), owner, name)
 	if err != nil {
-		return err
+		return nil, graphQLError(err)
 	}
-	return printDescription(respository.PullRequest)
-}
-
-func (c *Client) FetchComments(ctx context.Context, number int, name, owner string, opts ...Option) error {
-	cfg := options{}
-	if err := WithOptions(opts...)(&cfg); err != nil {
-		return err
+	c.transport.recordCost(rateLimit)
+	conversation, err := newConversation(repository.PullRequest)
+	if err != nil {
+		return nil, err
 	}
-	repository, err := FetchConversation(c.gql, ctx, int32(number), name, owner)
+	reviews, err := c.fetchRemainingReviews(ctx, owner, name, number, repository.PullRequest.Reviews)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	issueComments, err := c.fetchRemainingIssueComments(ctx, owner, name, number, repository.PullRequest.Comments)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := c.fetchRemainingReviewThreads(ctx, owner, name, number, repository.PullRequest.ReviewThreads)
+	if err != nil {
+		return nil, err
 	}
-	var comments []*comment
-	for _, edge := range repository.PullRequest.Reviews.Edges {
-		review := edge.Node
-		if review.Body != "" {
-			comment, err := newComment(review.Author.Login, review.Body, "", string(review.CreatedAt))
-			if err != nil {
-				return err
-			}
-			comments = append(comments, comment)
-		}
 
-		for _, edge := range review.Comments.Edges {
+	comments, err := reviewComments(reviews)
+	if err != nil {
+		return nil, err
+	}
+	for _, edge := range issueComments {
+		c := edge.Node
+		comment, err := newComment(c.Author.Login, c.Body, "", string(c.CreatedAt))
+		if err != nil {
+			return nil, err
+		}
+		comment.ID = databaseID(c.DatabaseId)
+		comments = append(comments, comment)
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	conversation.Comments = comments
+
+	for _, edge := range threads {
+		t := edge.Node
+		thread := &ReviewThread{
+			ID:         t.Id,
+			Path:       t.Path,
+			Side:       string(t.DiffSide),
+			IsResolved: t.IsResolved,
+			IsOutdated: t.IsOutdated,
+		}
+		if t.Line != nil {
+			thread.Line = int(*t.Line)
+		}
+		if t.OriginalLine != nil {
+			thread.OriginalLine = int(*t.OriginalLine)
+		}
+		threadComments, err := c.fetchRemainingThreadComments(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range threadComments {
 			c := edge.Node
 			comment, err := newComment(c.Author.Login, c.Body, c.DiffHunk, string(c.CreatedAt))
 			if err != nil {
-				return err
+				return nil, err
 			}
-			comments = append(comments, comment)
+			comment.ID = databaseID(c.DatabaseId)
+			comment.ThreadID = thread.ID
+			comment.Path = thread.Path
+			comment.Line = thread.Line
+			comment.Side = thread.Side
+			comment.OriginalLine = thread.OriginalLine
+			thread.Comments = append(thread.Comments, comment)
+		}
+		if len(thread.Comments) == 0 {
+			continue
 		}
+		conversation.Threads = append(conversation.Threads, thread)
 	}
-	for _, edge := range repository.PullRequest.Comments.Edges {
-		c := edge.Node
-		comment, err := newComment(c.Author.Login, c.Body, "", string(c.CreatedAt))
+	sort.Slice(conversation.Threads, func(i, j int) bool {
+		return conversation.Threads[i].CreatedAt().Before(conversation.Threads[j].CreatedAt())
+	})
+	return conversation, nil
+}
+
+func databaseID(id *int32) int {
+	if id == nil {
+		return 0
+	}
+	return int(*id)
+}
+
+// fetchRemainingReviews walks the pages of reviews following the first page,
+// which is fetched as part of the conversation.
+// reviewComments returns the reviews shown in a conversation. Reviews without
+// a body that only approve or request changes are kept, so that their ID can
+// be found to dismiss them. The rest merely group review comments, which are
+// shown with their threads.
+func reviewComments(reviews []*PullRequestReviewEdge) ([]*ConversationComment, error) {
+	var comments []*ConversationComment
+	for _, edge := range reviews {
+		review := edge.Node
+		if review.Body == "" && review.State != PullRequestReviewStateApproved && review.State != PullRequestReviewStateChangesRequested {
+			continue
+		}
+		comment, err := newComment(review.Author.Login, review.Body, "", string(review.CreatedAt))
 		if err != nil {
-			return err
+			return nil, err
 		}
+		comment.ID = databaseID(review.DatabaseId)
+		comment.ReviewState = string(review.State)
 		comments = append(comments, comment)
 	}
+	return comments, nil
+}
 
-	sort.Slice(comments, func(i, j int) bool {
-		return comments[i].createdAt.Before(comments[j].createdAt)
+func (c *Client) fetchRemainingReviews(ctx context.Context, owner, name string, number int, first *PullRequestReviewConnection) ([]*PullRequestReviewEdge, error) {
+	edges := first.Edges
+	for pageInfo := first.PageInfo; hasNextPage(pageInfo, 0, len(edges)); {
+		rateLimit, repository, err := FetchReviews(c.gql, ctx, int32(number), owner, name, *pageInfo.EndCursor)
+		if err != nil {
+			return nil, fmt.Errorf("FetchReviews: %w", graphQLError(err))
+		}
+		c.transport.recordCost(rateLimit)
+		edges = append(edges, repository.PullRequest.Reviews.Edges...)
+		pageInfo = repository.PullRequest.Reviews.PageInfo
+	}
+	return edges, nil
+}
+
+func (c *Client) fetchRemainingIssueComments(ctx context.Context, owner, name string, number int, first *IssueCommentConnection) ([]*IssueCommentEdge, error) {
+	edges := first.Edges
+	for pageInfo := first.PageInfo; hasNextPage(pageInfo, 0, len(edges)); {
+		rateLimit, repository, err := FetchIssueComments(c.gql, ctx, int32(number), owner, name, *pageInfo.EndCursor)
+		if err != nil {
+			return nil, fmt.Errorf("FetchIssueComments: %w", graphQLError(err))
+		}
+		c.transport.recordCost(rateLimit)
+		edges = append(edges, repository.PullRequest.Comments.Edges...)
+		pageInfo = repository.PullRequest.Comments.PageInfo
+	}
+	return edges, nil
+}
+
+func (c *Client) fetchRemainingReviewThreads(ctx context.Context, owner, name string, number int, first *PullRequestReviewThreadConnection) ([]*PullRequestReviewThreadEdge, error) {
+	edges := first.Edges
+	for pageInfo := first.PageInfo; hasNextPage(pageInfo, 0, len(edges)); {
+		rateLimit, repository, err := FetchReviewThreads(c.gql, ctx, int32(number), owner, name, *pageInfo.EndCursor)
+		if err != nil {
+			return nil, fmt.Errorf("FetchReviewThreads: %w", graphQLError(err))
+		}
+		c.transport.recordCost(rateLimit)
+		edges = append(edges, repository.PullRequest.ReviewThreads.Edges...)
+		pageInfo = repository.PullRequest.ReviewThreads.PageInfo
+	}
+	return edges, nil
+}
+
+func (c *Client) fetchRemainingThreadComments(ctx context.Context, thread *PullRequestReviewThread) ([]*PullRequestReviewCommentEdge, error) {
+	edges := thread.Comments.Edges
+	for pageInfo := thread.Comments.PageInfo; hasNextPage(pageInfo, 0, len(edges)); {
+		rateLimit, node, err := FetchThreadComments(c.gql, ctx, thread.Id, *pageInfo.EndCursor)
+		if err != nil {
+			return nil, fmt.Errorf("FetchThreadComments: %w", graphQLError(err))
+		}
+		c.transport.recordCost(rateLimit)
+		next, ok := node.Value.(*PullRequestReviewThread)
+		if !ok {
+			return nil, fmt.Errorf("FetchThreadComments: unexpected type: %T", node.Value)
+		}
+		edges = append(edges, next.Comments.Edges...)
+		pageInfo = next.Comments.PageInfo
+	}
+	return edges, nil
+}
+
+// ResolveThread marks a review thread as resolved, or as unresolved again if
+// resolved is false. The thread is either referenced by its node ID or by the
+// ID of one of its comments.
+func (c *Client) ResolveThread(ctx context.Context, owner, name string, number int, threadID string, resolved bool) error {
+	id, err := c.resolveThreadID(ctx, owner, name, number, threadID)
+	if err != nil {
+		return err
+	}
+	if resolved {
+		_, err = ResolveReviewThread(c.gql, ctx, ResolveReviewThreadInput{
+			ClientMutationId: &clientID,
+			ThreadId:         id,
+		})
+		if err != nil {
+			return fmt.Errorf("ResolveReviewThread: %w", graphQLError(err))
+		}
+		return nil
+	}
+	_, err = UnresolveReviewThread(c.gql, ctx, UnresolveReviewThreadInput{
+		ClientMutationId: &clientID,
+		ThreadId:         id,
 	})
+	if err != nil {
+		return fmt.Errorf("UnresolveReviewThread: %w", graphQLError(err))
+	}
+	return nil
+}
 
-	if cfg.last > 0 {
-		comments = comments[len(comments)-cfg.last:]
+// ReplyToComment posts a reply into the review thread containing the comment
+// with the given ID and returns the ID of the reply.
+func (c *Client) ReplyToComment(ctx context.Context, owner, name string, number, commentID int, body string) (int, error) {
+	conversation, err := c.fetchConversation(ctx, owner, name, number)
+	if err != nil {
+		return 0, err
+	}
+	thread := conversation.ThreadOf(commentID)
+	if thread == nil {
+		return 0, fmt.Errorf("comment %d is not part of a review thread on #%d", commentID, number)
+	}
+	payload, err := AddReviewThreadReply(c.gql, ctx, AddPullRequestReviewThreadReplyInput{
+		Body:                      body,
+		ClientMutationId:          &clientID,
+		PullRequestReviewThreadId: thread.ID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("AddReviewThreadReply: %w", graphQLError(err))
 	}
+	if payload == nil || payload.Comment == nil {
+		return 0, nil
+	}
+	return databaseID(payload.Comment.DatabaseId), nil
+}
 
-	return printComments(repository.PullRequest, comments)
+func (c *Client) resolveThreadID(ctx context.Context, owner, name string, number int, threadID string) (string, error) {
+	commentID, err := strconv.Atoi(threadID)
+	if err != nil {
+		return threadID, nil
+	}
+	conversation, err := c.fetchConversation(ctx, owner, name, number)
+	if err != nil {
+		return "", err
+	}
+	thread := conversation.ThreadOf(commentID)
+	if thread == nil {
+		return "", fmt.Errorf("thread %d not found on #%d", commentID, number)
+	}
+	return thread.ID, nil
 }
 
 var clientID = "re"
 
 func (c *Client) MarkAsReady(ctx context.Context, owner, name string, number int) error {
-	repository, err := FetchPullRequestID(c.gql, ctx, owner, name, int32(number))
+	rateLimit, repository, err := FetchPullRequestID(c.gql, ctx, owner, name, int32(number))
 	if err != nil {
-		return err
+		return graphQLError(err)
 	}
+	c.transport.recordCost(rateLimit)
 	_, err = MarkAsReady(c.gql, ctx, MarkPullRequestReadyForReviewInput{
 		ClientMutationId: &clientID,
 		PullRequestId:    repository.PullRequest.Id,
 	})
+	if err != nil {
+		return graphQLError(err)
+	}
+	return nil
+}
+
+// Quotas returns the API rate limits as last reported by GitHub.
+func (c *Client) Quotas() []Quota {
+	return c.transport.Quotas()
+}
+
+// FetchChecks returns the check runs and commit statuses of the head commit of
+// a pull request.
+func (c *Client) FetchChecks(ctx context.Context, owner, name string, number int) ([]Check, error) {
+	// Checks are polled while they run, a cached result would hide progress.
+	rateLimit, repository, err := FetchChecks(c.gql, withoutCache(ctx), int32(number), owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("FetchChecks: %w", graphQLError(err))
+	}
+	c.transport.recordCost(rateLimit)
+	commits := repository.PullRequest.Commits
+	if commits == nil || len(commits.Nodes) == 0 {
+		return nil, nil
+	}
+	return newChecks(commits.Nodes[0].Commit)
+}
+
+// FetchReviewDecision returns whether a pull request is approved, has changes
+// requested or still requires a review. It is empty if the repository does not
+// require reviews.
+func (c *Client) FetchReviewDecision(ctx context.Context, owner, name string, number int) (string, error) {
+	rateLimit, repository, err := FetchMergeState(c.gql, withoutCache(ctx), int32(number), owner, name)
+	if err != nil {
+		return "", fmt.Errorf("FetchMergeState: %w", graphQLError(err))
+	}
+	c.transport.recordCost(rateLimit)
+	if repository.PullRequest.ReviewDecision == nil {
+		return "", nil
+	}
+	return string(*repository.PullRequest.ReviewDecision), nil
+}
+
+// MergePullRequest merges a pull request after verifying that it meets the
+// requirements to be merged. With auto-merge, it is merged right away if it
+// already meets them. It reports whether the pull request was merged, as
+// opposed to being queued for auto-merge.
+func (c *Client) MergePullRequest(ctx context.Context, owner, name string, number int, opts MergeOptions) (bool, error) {
+	if opts.Auto && opts.DeleteBranch {
+		return false, errors.New("MergePullRequest: cannot delete the branch of a pull request merged automatically")
+	}
+	rateLimit, repository, err := FetchMergeState(c.gql, withoutCache(ctx), int32(number), owner, name)
+	if err != nil {
+		return false, fmt.Errorf("FetchMergeState: %w", graphQLError(err))
+	}
+	c.transport.recordCost(rateLimit)
+	pr := repository.PullRequest
+	if blockers := mergeBlockers(pr, opts.Auto); len(blockers) > 0 {
+		return false, &MergeBlockedError{Number: number, Blockers: blockers}
+	}
+	method := opts.Method
+	if method == "" {
+		method = PullRequestMergeMethodMerge
+	}
+
+	if opts.Auto && len(mergeBlockers(pr, false)) > 0 {
+		_, err := EnableAutoMerge(c.gql, ctx, EnablePullRequestAutoMergeInput{
+			ClientMutationId: &clientID,
+			ExpectedHeadOid:  &pr.HeadRefOid,
+			MergeMethod:      &method,
+			PullRequestId:    pr.Id,
+		})
+		if err != nil {
+			return false, fmt.Errorf("EnableAutoMerge: %w", graphQLError(err))
+		}
+		return false, nil
+	}
+
+	_, err = MergePullRequest(c.gql, ctx, MergePullRequestInput{
+		ClientMutationId: &clientID,
+		ExpectedHeadOid:  &pr.HeadRefOid,
+		MergeMethod:      &method,
+		PullRequestId:    pr.Id,
+	})
+	if err != nil {
+		return false, fmt.Errorf("MergePullRequest: %w", graphQLError(err))
+	}
+	if opts.DeleteBranch && pr.HeadRef != nil {
+		_, err := DeleteRef(c.gql, ctx, DeleteRefInput{
+			ClientMutationId: &clientID,
+			RefId:            pr.HeadRef.Id,
+		})
+		if err != nil {
+			return true, fmt.Errorf("DeleteRef: %w", graphQLError(err))
+		}
+	}
+	return true, nil
+}
+
+// DismissReview dismisses a review of a pull request. The review is either
+// referenced by its node ID or by the numeric ID shown on GitHub.
+func (c *Client) DismissReview(ctx context.Context, owner, name string, number int, reviewID, message string) error {
+	id, err := c.resolveReviewID(ctx, owner, name, number, reviewID)
 	if err != nil {
 		return err
 	}
+	_, err = DismissReview(c.gql, ctx, DismissPullRequestReviewInput{
+		ClientMutationId:    &clientID,
+		Message:             message,
+		PullRequestReviewId: id,
+	})
+	if err != nil {
+		return fmt.Errorf("DismissReview: %w", graphQLError(err))
+	}
 	return nil
 }
 
+func (c *Client) resolveReviewID(ctx context.Context, owner, name string, number int, reviewID string) (string, error) {
+	databaseID, err := strconv.Atoi(reviewID)
+	if err != nil {
+		return reviewID, nil
+	}
+	var after *string
+	for {
+		rateLimit, repository, err := FetchReviewIDs(c.gql, ctx, owner, name, int32(number), after)
+		if err != nil {
+			return "", fmt.Errorf("FetchReviewIDs: %w", graphQLError(err))
+		}
+		c.transport.recordCost(rateLimit)
+		reviews := repository.PullRequest.Reviews
+		for _, edge := range reviews.Edges {
+			review := edge.Node
+			if review.DatabaseId != nil && int(*review.DatabaseId) == databaseID {
+				return review.Id, nil
+			}
+		}
+		if !hasNextPage(reviews.PageInfo, 0, 0) {
+			return "", fmt.Errorf("review %d not found on #%d", databaseID, number)
+		}
+		after = reviews.PageInfo.EndCursor
+	}
+}
+
 type authenticatedTransport struct {
 	transport   http.RoundTripper
 	accessToken string
@@ -1,37 +1,80 @@
 package re
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Command struct {
-	client *Client
-	org    string
-	name   string
+	client   *Client
+	org      string
+	name     string
+	renderer Renderer
+	out      io.Writer
+	webURL   string
+	browser  string
+	pager    string
+	repos    []string
+	// git is the repository the working directory is in.
+	git Git
+	// base is the repository pull requests are opened against and read from,
+	// head the one branches are pushed to. They differ when working from a
+	// fork.
+	base Remote
+	head Remote
+	// store holds the data synced for offline use. Commands read from it
+	// instead of the API if offline is set.
+	store   *Store
+	offline bool
 }
 
 func NewCommand(ctx context.Context, config Config, opts ...CommandOption) (*Command, error) {
-	cfg := commandOptions{}
+	cfg := commandOptions{
+		git: NewExecGit(""),
+		out: os.Stdout,
+	}
 	if err := WithCommandOptions(opts...)(&cfg); err != nil {
 		return nil, err
 	}
-	client, err := NewClient(ctx, config)
+	store, err := NewStore()
 	if err != nil {
 		return nil, err
 	}
 	command := &Command{
-		client: client,
+		renderer: NewRenderer(cfg.format, config.DiffTool),
+		out:      cfg.out,
+		webURL:   config.WebURL(),
+		browser:  config.Browser,
+		pager:    config.Pager,
+		repos:    config.Repos,
+		git:      cfg.git,
+		store:    store,
+		offline:  cfg.offline,
 	}
-	if cfg.requireGit {
-		org, name, err := GetRepositoryAndOrgName()
+	// Offline, no request may be made, not even to look up the login.
+	if !cfg.offline {
+		command.client, err = NewClient(ctx, config)
 		if err != nil {
 			return nil, err
 		}
-		command.org = org
-		command.name = name
+	}
+	base, head, err := DetectRemotes(ctx, cfg.git, config.BaseRemote, config.HeadRemote)
+	if err != nil {
+		if cfg.requireGit {
+			return nil, err
+		}
+	} else {
+		command.base, command.head = base, head
+		command.org, command.name = base.Owner, base.Name
 	}
 	return command, nil
 }
@@ -41,23 +84,397 @@ func (c *Command) ApprovePullRequest(ctx context.Context, pr int, message string
 }
 
 func (c *Command) CommentPullRequest(ctx context.Context, pr int, message string) error {
+	if message == "" {
+		return errors.New("CommentPullRequest: message is required")
+	}
+	if c.offline {
+		return c.queueReview(pr, CreatePullRequestReview{Event: "COMMENT", Body: message})
+	}
 	return c.client.ReviewPullRequest(ctx, c.org, c.name, pr, "COMMENT", message)
 }
 
+func (c *Command) RequestChanges(ctx context.Context, pr int, message string) error {
+	if message == "" {
+		return errors.New("RequestChanges: message is required")
+	}
+	return c.client.ReviewPullRequest(ctx, c.org, c.name, pr, "REQUEST_CHANGES", message)
+}
+
+func (c *Command) DismissReview(ctx context.Context, pr int, reviewID, message string) error {
+	if message == "" {
+		return errors.New("DismissReview: message is required")
+	}
+	return c.client.DismissReview(ctx, c.org, c.name, pr, reviewID, message)
+}
+
+// ResolveThread resolves the review thread started by the comment with the
+// given ID, or unresolves it again if resolved is false.
+func (c *Command) ResolveThread(ctx context.Context, pr int, thread string, resolved bool) error {
+	return c.client.ResolveThread(ctx, c.org, c.name, pr, thread, resolved)
+}
+
+// ReplyToComment answers a review comment within its thread.
+func (c *Command) ReplyToComment(ctx context.Context, pr int, commentID, message string) error {
+	if message == "" {
+		return errors.New("ReplyToComment: message is required")
+	}
+	id, err := strconv.Atoi(commentID)
+	if err != nil {
+		return fmt.Errorf("invalid comment ID %q", commentID)
+	}
+	reply, err := c.client.ReplyToComment(ctx, c.org, c.name, pr, id, message)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Replied with comment #%d\n", reply)
+	return nil
+}
+
+func (c *Command) CommentOnLines(ctx context.Context, pr int, location, side, message string) error {
+	if message == "" {
+		return errors.New("CommentOnLines: message is required")
+	}
+	comment, err := NewReviewComment(location, side, message)
+	if err != nil {
+		return err
+	}
+	if c.offline {
+		files, err := c.store.Diff(c.org, c.name, pr)
+		if err != nil {
+			return err
+		}
+		if err := validateReviewComment(comment, files); err != nil {
+			return err
+		}
+		return c.queueReview(pr, CreatePullRequestReview{
+			Event:    "COMMENT",
+			Comments: []ReviewComment{comment},
+		})
+	}
+	return c.client.CommentOnLines(ctx, c.org, c.name, pr, comment)
+}
+
+func (c *Command) AddDraftComment(ctx context.Context, pr int, location, side, message string) error {
+	if message == "" {
+		return errors.New("AddDraftComment: message is required")
+	}
+	comment, err := NewReviewComment(location, side, message)
+	if err != nil {
+		return err
+	}
+	if err := c.client.ValidateReviewComments(ctx, c.org, c.name, pr, comment); err != nil {
+		return err
+	}
+	draft, err := LoadDraft(c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	draft.Comments = append(draft.Comments, comment)
+	return draft.Save()
+}
+
+func (c *Command) PrintDraft(ctx context.Context, pr int) error {
+	draft, err := LoadDraft(c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	return c.renderer.RenderDraft(c.out, draft)
+}
+
+func (c *Command) EditDraftComment(ctx context.Context, pr, index int, message string) error {
+	if message == "" {
+		return errors.New("EditDraftComment: message is required")
+	}
+	draft, err := LoadDraft(c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	comment, err := draft.Comment(index)
+	if err != nil {
+		return err
+	}
+	comment.Body = message
+	return draft.Save()
+}
+
+func (c *Command) DeleteDraftComment(ctx context.Context, pr, index int) error {
+	draft, err := LoadDraft(c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	if err := draft.RemoveComment(index); err != nil {
+		return err
+	}
+	return draft.Save()
+}
+
+func (c *Command) SubmitDraft(ctx context.Context, pr int, event, message string) error {
+	event, err := parseReviewEvent(event)
+	if err != nil {
+		return err
+	}
+	draft, err := LoadDraft(c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	if len(draft.Comments) == 0 && message == "" && event != "APPROVE" {
+		return fmt.Errorf("SubmitDraft: draft of #%d is empty", pr)
+	}
+	// The diff may have changed since the comments were added, validate again
+	// to report stale comments before the whole review gets rejected.
+	if err := c.client.ValidateReviewComments(ctx, c.org, c.name, pr, draft.Comments...); err != nil {
+		return err
+	}
+	err = c.client.SubmitReview(ctx, c.org, c.name, pr, CreatePullRequestReview{
+		Event:    event,
+		Body:     message,
+		Comments: draft.Comments,
+	})
+	if err != nil {
+		return err
+	}
+	return draft.Delete()
+}
+
+func (c *Command) DiscardDraft(ctx context.Context, pr int) error {
+	draft, err := LoadDraft(c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	return draft.Delete()
+}
+
+func (c *Command) ReviewInteractively(ctx context.Context, pr int) error {
+	return c.client.ReviewInteractively(ctx, c.org, c.name, pr)
+}
+
 func (c *Command) PrintDiff(ctx context.Context, pr int) error {
-	return c.client.FetchDiff(ctx, c.org, c.name, pr, false)
+	var (
+		files []FileDiff
+		err   error
+	)
+	if c.offline {
+		files, err = c.store.Diff(c.org, c.name, pr)
+	} else {
+		files, err = c.client.FetchDiff(ctx, c.org, c.name, pr)
+	}
+	if err != nil {
+		return err
+	}
+	return c.renderer.RenderDiff(c.out, files)
 }
 
 func (c *Command) MarkPullRequestReady(ctx context.Context, pr int) error {
 	return c.client.MarkAsReady(ctx, c.org, c.name, pr)
 }
 
+// Quotas returns the API rate limits as last reported by GitHub.
+func (c *Command) Quotas() []Quota {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Quotas()
+}
+
+// PrintChecks lists the CI checks of a pull request.
+func (c *Command) PrintChecks(ctx context.Context, pr int) error {
+	checks, err := c.client.FetchChecks(ctx, c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	return c.renderer.RenderChecks(c.out, checks)
+}
+
+// logPollInterval is how often a job is polled while following its progress.
+var logPollInterval = 5 * time.Second
+
+// PrintLogs prints the tail of the log of a failing check of a pull request,
+// or of the check with the given name. With follow, it polls the log of the
+// job while it is still in progress, printing the lines as they are written,
+// and prints the tail of the failed step once the job completed.
+func (c *Command) PrintLogs(ctx context.Context, pr int, name string, follow bool, lines int) error {
+	checks, err := c.client.FetchChecks(ctx, c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	check, err := selectCheck(checks, name, follow)
+	if err != nil {
+		return fmt.Errorf("#%d: %w", pr, err)
+	}
+	if check.ID == 0 {
+		return fmt.Errorf("check %s is a commit status without logs: %s", check.Name, check.URL)
+	}
+
+	job, err := c.client.FetchJob(ctx, c.org, c.name, check.ID)
+	if err != nil {
+		return err
+	}
+	printed := 0
+	for follow && !job.Completed() {
+		log, err := c.client.FetchJobLog(ctx, c.org, c.name, job.ID)
+		// The log of a job that has not started yet does not exist.
+		var apiErr *APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound) {
+			return err
+		}
+		if log != "" {
+			logLines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+			for _, line := range logLines[min(printed, len(logLines)):] {
+				fmt.Fprintln(c.out, trimLogTimestamp(line))
+			}
+			printed = max(printed, len(logLines))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(logPollInterval):
+		}
+		if job, err = c.client.FetchJob(ctx, c.org, c.name, check.ID); err != nil {
+			return err
+		}
+	}
+	if !job.Completed() {
+		return fmt.Errorf("job %s is still %s, use --follow to wait for it", job.Name, strings.ReplaceAll(job.Status, "_", " "))
+	}
+
+	log, err := c.client.FetchJobLog(ctx, c.org, c.name, job.ID)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("%s: %s", job.Name, job.Conclusion)
+	if step := job.FailedStep(); step != nil {
+		header += fmt.Sprintf(" at step %q", step.Name)
+		log = stepLog(log, step)
+	}
+	fmt.Fprintln(c.out, yellow.Render(header))
+	for _, line := range logTail(log, lines) {
+		fmt.Fprintln(c.out, line)
+	}
+	return nil
+}
+
+// selectCheck returns the check with the given name. Without a name, it
+// returns the first failing check, or the first one in progress if pending
+// is set.
+func selectCheck(checks []Check, name string, pending bool) (Check, error) {
+	if name != "" {
+		for _, check := range checks {
+			if strings.EqualFold(check.Name, name) || strings.EqualFold(check.Workflow+" / "+check.Name, name) {
+				return check, nil
+			}
+		}
+		return Check{}, fmt.Errorf("no check named %q", name)
+	}
+	for _, check := range checks {
+		if check.Status() == StatusStateFailure || check.Status() == StatusStateError {
+			return check, nil
+		}
+	}
+	if pending {
+		for _, check := range checks {
+			if check.Status() == StatusStatePending {
+				return check, nil
+			}
+		}
+	}
+	return Check{}, errors.New("no failing checks")
+}
+
+// RerunChecks re-runs the workflow runs and check suites of a pull request
+// with failed checks. It prints the URL of the pull request and of every
+// re-run workflow run, which stays the same across attempts, so that the
+// progress can be followed with re watch or in the browser.
+func (c *Command) RerunChecks(ctx context.Context, pr int, failedOnly bool) error {
+	checks, err := c.client.FetchChecks(ctx, c.org, c.name, pr)
+	if err != nil {
+		return err
+	}
+	targets := rerunTargets(checks)
+	if len(targets) == 0 {
+		return fmt.Errorf("#%d: no failed checks to re-run", pr)
+	}
+	repoURL := c.webURL + "/" + c.org + "/" + c.name
+	fmt.Fprintf(c.out, "Re-running checks of #%d: %s/pull/%d\n", pr, repoURL, pr)
+	for _, target := range targets {
+		if target.RunID == 0 {
+			if err := c.client.RerequestCheckSuite(ctx, c.org, c.name, target.SuiteID); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.out, "%s/pull/%d/checks\t%s (check suite)\n", repoURL, pr, target.Workflow)
+			continue
+		}
+		if err := c.client.RerunWorkflowRun(ctx, c.org, c.name, target.RunID, failedOnly); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "%s/actions/runs/%d\t%s\n", repoURL, target.RunID, target.Workflow)
+	}
+	fmt.Fprintf(c.out, "Run re watch %d to wait for the checks to complete\n", pr)
+	return nil
+}
+
+// MergePullRequest merges a pull request or enables auto-merge for it.
+func (c *Command) MergePullRequest(ctx context.Context, pr int, opts MergeOptions) error {
+	merged, err := c.client.MergePullRequest(ctx, c.org, c.name, pr, opts)
+	if err != nil {
+		return err
+	}
+	if !merged {
+		fmt.Fprintf(c.out, "Enabled auto-merge for #%d\n", pr)
+		return nil
+	}
+	fmt.Fprintf(c.out, "Merged #%d\n", pr)
+	return nil
+}
+
 func (c *Command) PrintComments(ctx context.Context, pr int) error {
-	return c.client.FetchComments(ctx, pr, c.org, c.name)
+	var (
+		conversation *Conversation
+		err          error
+	)
+	if c.offline {
+		conversation, err = c.store.Conversation(c.org, c.name, pr)
+	} else {
+		conversation, err = c.client.FetchComments(ctx, pr, c.org, c.name)
+	}
+	if err != nil {
+		return err
+	}
+	return c.page(func(w io.Writer) error {
+		return c.renderer.RenderConversation(w, conversation)
+	})
+}
+
+// page pipes the output of render through the configured pager. Output in a
+// machine-readable format is never paged.
+func (c *Command) page(render func(w io.Writer) error) error {
+	if _, ok := c.renderer.(TerminalRenderer); !ok || c.pager == "" {
+		return render(c.out)
+	}
+	var b bytes.Buffer
+	if err := render(&b); err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", c.pager)
+	cmd.Stdin = &b
+	cmd.Stdout = c.out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
 }
 
 func (c *Command) PrintNotifications(ctx context.Context) error {
-	return c.client.FetchNotifiations(ctx)
+	var (
+		items []InboxItem
+		err   error
+	)
+	if c.offline {
+		items, err = c.store.Notifications()
+	} else {
+		items, err = c.client.FetchNotifiations(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return c.renderer.RenderInbox(c.out, items)
 }
 
 func (c *Command) PrintPendingReviews(ctx context.Context, limit int, includeTeamReview bool) error {
@@ -65,27 +482,39 @@ func (c *Command) PrintPendingReviews(ctx context.Context, limit int, includeTea
 	if includeTeamReview {
 		query = "is:pr is:open review-requested:@me sort:created-asc"
 	}
-	return c.client.FetchMyPullRequestReviewQueue(ctx, query, c.name, limit)
+	pullRequests, err := c.client.FetchMyPullRequestReviewQueue(ctx, query, c.name, limit)
+	if err != nil {
+		return err
+	}
+	return c.printPullRequests(pullRequests)
 }
 
 func (c *Command) ListPullRequests(ctx context.Context, limit int, includeClosed bool) error {
-	return c.client.FetchPullRequests(ctx, limit, c.org, c.name, includeClosed)
+	if c.offline {
+		return c.listSyncedPullRequests(limit, includeClosed)
+	}
+	pullRequests, err := c.client.FetchPullRequests(ctx, limit, c.org, c.name, includeClosed)
+	if err != nil {
+		return err
+	}
+	return c.printPullRequests(pullRequests)
 }
 
 func (c *Command) PrintMyPullRequests(ctx context.Context, limit int) error {
-	return c.client.FetchMyPullRequests(ctx, limit)
+	pullRequests, err := c.client.FetchMyPullRequests(ctx, limit)
+	if err != nil {
+		return err
+	}
+	return c.printPullRequests(pullRequests)
 }
 
-func (c *Command) CheckoutPullRequest(ctx context.Context, pr int) error {
-	return CheckoutPullRequest(pr)
+func (c *Command) printPullRequests(pullRequests []PullRequestSummary) error {
+	return c.renderer.RenderPullRequests(c.out, pullRequests)
 }
 
 func (c *Command) OpenPullRequest(ctx context.Context, pr int) error {
-	endpoint := "https://github.com"
-	if ghe := os.Getenv("GITHUB_ENTERPRISE_URL"); ghe != "" {
-		endpoint = ghe
-	}
-	cmd := exec.Command("chromium", endpoint+"/"+c.org+"/"+c.name+"/pull/"+fmt.Sprint(pr))
+	url := c.webURL + "/" + c.org + "/" + c.name + "/pull/" + fmt.Sprint(pr)
+	cmd := exec.Command("sh", "-c", c.browser+` "$1"`, "sh", url)
 	if err := cmd.Start(); err != nil {
 		return err
 	}
@@ -93,36 +522,83 @@ func (c *Command) OpenPullRequest(ctx context.Context, pr int) error {
 }
 
 func (c *Command) CreatePullRequest(ctx context.Context) error {
-	if err := PushToOrigin(); err != nil {
+	branch, err := c.git.CurrentBranch(ctx)
+	if err != nil {
 		return err
 	}
-	title, body, err := GetTitleAndBody()
+	output, err := c.git.Push(ctx, c.head.RemoteName, branch, false)
 	if err != nil {
 		return err
 	}
-	branch, err := CurrentBranch()
+	fmt.Fprintln(c.out, output)
+	title, body, err := CommitTitleAndBody(ctx, c.git, "HEAD")
 	if err != nil {
 		return err
 	}
-	defaultBranch, err := GetDefaultBranch()
+	defaultBranch, err := c.git.DefaultBranch(ctx, c.base.RemoteName)
 	if err != nil {
 		return err
 	}
-	return c.client.CreatePullRequest(ctx, c.org, c.name, CreatePullRequest{
+	args := CreatePullRequest{
 		Title: title,
 		Head:  branch,
 		Base:  defaultBranch,
 		Body:  body,
 		Draft: true,
-	})
+	}
+	// A branch pushed to a fork is referenced as owner:branch.
+	if !c.head.SameRepository(c.base) {
+		if !strings.EqualFold(c.head.Host, c.base.Host) {
+			return fmt.Errorf("CreatePullRequest: %s and %s are on different hosts", c.head, c.base)
+		}
+		args.Head = c.head.Owner + ":" + branch
+		args.HeadRepository = c.head.Name
+	}
+	number, err := c.client.CreatePullRequest(ctx, c.org, c.name, args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "Created pull request", number)
+	return nil
 }
 
+// PushBranch force-pushes the current branch, unless the branch changed on
+// the remote since it was last fetched.
 func (c *Command) PushBranch(ctx context.Context) error {
-	return UpdateOrigin()
+	defaultBranch, err := c.git.DefaultBranch(ctx, c.head.RemoteName)
+	if err != nil {
+		return err
+	}
+	branch, err := c.git.CurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	if branch == defaultBranch {
+		return fmt.Errorf("PushBranch: not supported for default branch %q", defaultBranch)
+	}
+	output, err := c.git.Push(ctx, c.head.RemoteName, branch, true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, output)
+	return nil
 }
 
 type commandOptions struct {
+	git        Git
 	requireGit bool
+	offline    bool
+	format     Format
+	out        io.Writer
+}
+
+// WithGit sets the repository that commands operate on, which defaults to
+// running git in the current directory.
+func WithGit(git Git) CommandOption {
+	return func(o *commandOptions) error {
+		o.git = git
+		return nil
+	}
 }
 
 func WithRequireGit(enabled bool) CommandOption {
@@ -132,6 +608,36 @@ func WithRequireGit(enabled bool) CommandOption {
 	}
 }
 
+// WithOffline reads pull requests from the data synced with [Command.Sync]
+// instead of the API, and queues comments to be submitted on the next sync.
+func WithOffline(enabled bool) CommandOption {
+	return func(o *commandOptions) error {
+		o.offline = enabled
+		return nil
+	}
+}
+
+// WithOutput sets the writer that results are rendered to, which defaults to
+// standard output.
+func WithOutput(w io.Writer) CommandOption {
+	return func(o *commandOptions) error {
+		o.out = w
+		return nil
+	}
+}
+
+// WithFormat sets the output format of listing commands.
+func WithFormat(format string) CommandOption {
+	return func(o *commandOptions) error {
+		f, err := ParseFormat(format)
+		if err != nil {
+			return err
+		}
+		o.format = f
+		return nil
+	}
+}
+
 // CommandOption is a functional option for flexible and extensible
 // configuration of [*Command], allowing modification of internal state or
 // behavior during construction.
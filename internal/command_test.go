@@ -1,9 +1,21 @@
 package re_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	re "github.com/konradreiche/re/internal"
 	"github.com/konradreiche/re/internal/commandtest"
+	"github.com/konradreiche/re/internal/commandtest/fakegit"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub/graph/model"
 )
 
 func TestListPullRequests(t *testing.T) {
@@ -12,3 +24,518 @@ func TestListPullRequests(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestListPullRequestsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	command := commandtest.New(t, re.WithFormat("json"), re.WithOutput(&buf))
+	if err := command.ListPullRequests(t.Context(), 20, false); err != nil {
+		t.Fatal(err)
+	}
+	var got []re.PullRequestSummary
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []re.PullRequestSummary{
+		{
+			Number:         1,
+			Author:         "foo",
+			Repository:     "test-repo",
+			HeadRef:        "main",
+			CreatedAt:      time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			Comments:       1,
+			Status:         re.StatusStateSuccess,
+			ViewerIsAuthor: true,
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestListPullRequestsPagination(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 20, want: 20},
+		{limit: 150, want: 150},
+		{limit: 0, want: 251},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.limit), func(t *testing.T) {
+			var buf bytes.Buffer
+			fake := fakegithub.New(t, fakegithub.WithPullRequests(250))
+			command := commandtest.NewWithGitHub(t, fake, re.WithFormat("json"), re.WithOutput(&buf))
+			if err := command.ListPullRequests(t.Context(), tt.limit, false); err != nil {
+				t.Fatal(err)
+			}
+			var got []re.PullRequestSummary
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d pull requests, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		remotes func(git *fakegit.FakeGit)
+		want    fakegithub.CreatedPullRequest
+	}{
+		{
+			name: "branch",
+			remotes: func(git *fakegit.FakeGit) {
+				git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+			},
+			want: fakegithub.CreatedPullRequest{
+				Owner: "foo",
+				Name:  "test-repo",
+				Title: "Add greeting",
+				Head:  "greeting",
+				Base:  "main",
+				Body:  "Says hello to everyone.",
+				Draft: true,
+			},
+		},
+		{
+			name: "fork",
+			remotes: func(git *fakegit.FakeGit) {
+				git.SetRemote("origin", "git@github.com:bar/test-repo-fork.git", "main")
+				git.SetRemote("upstream", "https://github.com/foo/test-repo.git", "trunk")
+			},
+			want: fakegithub.CreatedPullRequest{
+				Owner:          "foo",
+				Name:           "test-repo",
+				Title:          "Add greeting",
+				Head:           "bar:greeting",
+				HeadRepository: "test-repo-fork",
+				Base:           "trunk",
+				Body:           "Says hello to everyone.",
+				Draft:          true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := fakegit.New()
+			tt.remotes(git)
+			if err := git.Switch(t.Context(), "greeting", "main"); err != nil {
+				t.Fatal(err)
+			}
+			git.Commit("Add greeting\n\nSays hello\nto everyone.")
+
+			fake := fakegithub.New(t)
+			command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(io.Discard))
+			if err := command.CreatePullRequest(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]fakegithub.CreatedPullRequest{tt.want}, fake.CreatedPullRequests()); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+			// The branch is pushed to the head repository.
+			if diff := cmp.Diff(git.Branch("greeting"), git.Remote("origin").Refs["refs/heads/greeting"]); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPushBranch(t *testing.T) {
+	git := fakegit.New()
+	origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	command := commandtest.New(t, re.WithGit(git), re.WithOutput(io.Discard))
+	if err := command.PushBranch(t.Context()); err == nil {
+		t.Error("got no error, want an error for pushing the default branch")
+	}
+
+	if err := git.Switch(t.Context(), "greeting", "main"); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Add greeting")
+	if err := command.PushBranch(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	// An amended commit is force-pushed.
+	if err := git.Switch(t.Context(), "amended", "main"); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Add greeting to everyone")
+	if err := git.DeleteBranch(t.Context(), "greeting"); err != nil {
+		t.Fatal(err)
+	}
+	if err := git.Switch(t.Context(), "greeting", "amended"); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.PushBranch(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"greeting", "greeting"}, origin.Pushes); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if diff := cmp.Diff(git.Branch("greeting"), origin.Refs["refs/heads/greeting"]); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+// checkoutState returns the branch checked out in the main worktree and the
+// commit, upstream and pull request of a branch.
+func checkoutState(t *testing.T, git *fakegit.FakeGit, branch string) []string {
+	t.Helper()
+	current, err := git.CurrentBranch(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, ref := re.BranchUpstream(t.Context(), git, branch)
+	pr, err := git.Config(t.Context(), "branch."+branch+".re-pull-request")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []string{current, git.Branch(branch), remote, ref, pr}
+}
+
+func TestCheckoutPullRequest(t *testing.T) {
+	git := fakegit.New()
+	origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	pushed := git.CommitRemote(origin, "refs/heads/greeting", "Add greeting")
+	fake := fakegithub.New(t, fakegithub.WithHead("greeting", "", false))
+	command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(io.Discard))
+	checkout := func() error {
+		return command.CheckoutPullRequest(t.Context(), 1, re.CheckoutOptions{})
+	}
+
+	if err := checkout(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"greeting", pushed, "origin", "refs/heads/greeting", "1"}
+	if diff := cmp.Diff(want, checkoutState(t, git, "greeting")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	// Local commits that were not pushed yet are kept.
+	local := git.Commit("Greet everyone")
+	if err := checkout(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(local, git.Branch("greeting")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	// Commits pushed on top of them are fast-forwarded.
+	origin.Refs["refs/heads/greeting"] = local
+	pushed = git.CommitRemote(origin, "refs/heads/greeting", "Greet everyone twice")
+	if err := checkout(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(pushed, git.Branch("greeting")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	git.Commit("Greet nobody")
+	git.CommitRemote(origin, "refs/heads/greeting", "Greet somebody")
+	if err := checkout(); err == nil {
+		t.Error("got no error, want an error for a branch that diverged")
+	}
+}
+
+func TestCheckoutPullRequestFork(t *testing.T) {
+	tests := []struct {
+		name                string
+		ref                 string
+		maintainerCanModify bool
+		// want is the local branch and its upstream.
+		want []string
+	}{
+		{
+			name:                "maintainer-can-modify",
+			ref:                 "greeting",
+			maintainerCanModify: true,
+			want:                []string{"greeting", "bar", "refs/heads/greeting"},
+		},
+		{
+			name: "read-only",
+			ref:  "greeting",
+			want: []string{"greeting", "origin", "refs/pull/1/head"},
+		},
+		{
+			name:                "default-branch",
+			ref:                 "main",
+			maintainerCanModify: true,
+			want:                []string{"bar/main", "bar", "refs/heads/main"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := fakegit.New()
+			origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+			fork := git.Hosted("git@github.com:bar/test-repo.git")
+			pushed := git.CommitRemote(fork, "refs/heads/"+tt.ref, "Add greeting")
+			origin.Refs["refs/pull/1/head"] = pushed
+
+			fake := fakegithub.New(t, fakegithub.WithHead(tt.ref, "bar", tt.maintainerCanModify))
+			command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(io.Discard))
+			if err := command.CheckoutPullRequest(t.Context(), 1, re.CheckoutOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			branch := tt.want[0]
+			want := []string{branch, pushed, tt.want[1], tt.want[2], "1"}
+			if diff := cmp.Diff(want, checkoutState(t, git, branch)); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+			// The remote of the fork uses the protocol of the base remote.
+			if remote := git.Remote("bar"); tt.maintainerCanModify && (remote == nil || remote.URL != fork.URL) {
+				t.Errorf("got remote %+v, want a remote for %s", remote, fork.URL)
+			}
+		})
+	}
+}
+
+func TestCheckoutPullRequestWorktree(t *testing.T) {
+	git := fakegit.New()
+	origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	pushed := git.CommitRemote(origin, "refs/heads/greeting", "Add greeting")
+	fake := fakegithub.New(t, fakegithub.WithHead("greeting", "", false))
+	var buf bytes.Buffer
+	command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(&buf))
+	checkout := func() error {
+		return command.CheckoutPullRequest(t.Context(), 1, re.CheckoutOptions{Worktree: true})
+	}
+
+	if err := checkout(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"main", pushed, "origin", "refs/heads/greeting", "1"}
+	if diff := cmp.Diff(want, checkoutState(t, git, "greeting")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	worktrees, err := git.Worktrees(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWorktrees := []re.Worktree{
+		{Path: "/src/repo", Branch: "main"},
+		{Path: "/src/repo-pr-1", Branch: "greeting"},
+	}
+	if diff := cmp.Diff(wantWorktrees, worktrees); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	// A branch checked out in a worktree can only be updated there, so it is
+	// reported if it is behind.
+	git.CommitRemote(origin, "refs/heads/greeting", "Greet everyone")
+	buf.Reset()
+	if err := checkout(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(pushed, git.Branch("greeting")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	wantOutput := "Branch greeting in /src/repo-pr-1 is behind #1, run git pull --ff-only there to update it\n" +
+		"Checked out #1 into branch greeting in /src/repo-pr-1\n"
+	if diff := cmp.Diff(wantOutput, buf.String()); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestPruneWorktrees(t *testing.T) {
+	git := fakegit.New()
+	origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	pushed := git.CommitRemote(origin, "refs/heads/greeting", "Add greeting")
+	fake := fakegithub.New(t,
+		fakegithub.WithHead("greeting", "", false),
+		fakegithub.WithState(model.PullRequestStateMerged),
+	)
+	var buf bytes.Buffer
+	command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(&buf))
+	if err := command.CheckoutPullRequest(t.Context(), 1, re.CheckoutOptions{Worktree: true}); err != nil {
+		t.Fatal(err)
+	}
+	// Worktrees not checked out for a pull request are kept.
+	if err := git.AddWorktree(t.Context(), "/src/scratch", "scratch", "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Commits made after the pull request was merged are kept.
+	origin.Refs["refs/pull/1/head"] = pushed
+	local := git.CommitBranch("greeting", "Greet everyone")
+	if err := command.PruneWorktrees(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(local, git.Branch("greeting")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if !strings.Contains(buf.String(), "greeting has commits that are not part of the pull request") {
+		t.Errorf("got output %q, want the kept worktree to be reported", buf.String())
+	}
+
+	origin.Refs["refs/pull/1/head"] = local
+	if err := command.PruneWorktrees(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	worktrees, err := git.Worktrees(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []re.Worktree{
+		{Path: "/src/repo", Branch: "main"},
+		{Path: "/src/scratch", Branch: "scratch"},
+	}
+	if diff := cmp.Diff(want, worktrees); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if git.Branch("greeting") != "" {
+		t.Error("got branch greeting, want it to be deleted")
+	}
+}
+
+func TestRerunChecks(t *testing.T) {
+	git := fakegit.New()
+	git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	fake := fakegithub.New(t, fakegithub.WithCheckRun("test", 0, model.CheckConclusionStateFailure))
+	var buf bytes.Buffer
+	command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(&buf))
+	if err := command.RerunChecks(t.Context(), 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"/repos/foo/test-repo/actions/runs/1/rerun-failed-jobs"}, fake.Reruns()); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	// The output points to where the re-run can be followed.
+	want := "Re-running checks of #1: https://github.com/foo/test-repo/pull/1\n" +
+		"https://github.com/foo/test-repo/actions/runs/1\tCI\n" +
+		"Run re watch 1 to wait for the checks to complete\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestDismissReviewWithoutBody(t *testing.T) {
+	fake := fakegithub.New(t, fakegithub.WithReview(model.PullRequestReviewStateApproved, ""))
+	command := commandtest.NewWithGitHub(t, fake, re.WithOutput(io.Discard))
+	if err := command.DismissReview(t.Context(), 1, "1", "Outdated"); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(model.PullRequestReviewStateDismissed, fake.ReviewState(1)); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestSubmitStack(t *testing.T) {
+	git := fakegit.New()
+	origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	if err := git.Switch(t.Context(), "greeting", "main"); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Add greeting")
+	if err := git.Switch(t.Context(), "farewell", "greeting"); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Add farewell")
+
+	fake := fakegithub.New(t)
+	command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(io.Discard))
+	if err := command.SubmitStack(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"greeting", "farewell"}, origin.Pushes); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	// Each pull request is based on the branch below it and points to its
+	// own row of the table.
+	var got []string
+	for _, pr := range fake.PullRequests() {
+		row := regexp.MustCompile("(?m)^\\| → \\| #(\\d+) \\|").FindStringSubmatch(pr.Body)
+		if row == nil {
+			t.Fatalf("#%d: no stack table in %q", pr.Number, pr.Body)
+		}
+		got = append(got, fmt.Sprintf("#%d %s on %s, table at #%s", pr.Number, pr.Head, pr.Base, row[1]))
+	}
+	want := []string{
+		"#2 greeting on main, table at #2",
+		"#3 farewell on greeting, table at #3",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestRestackStack(t *testing.T) {
+	git := fakegit.New()
+	origin := git.SetRemote("origin", "git@github.com:foo/test-repo.git", "main")
+	if err := git.Switch(t.Context(), "greeting", "main"); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Add greeting")
+	if err := git.Switch(t.Context(), "farewell", "greeting"); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Add farewell")
+
+	fake := fakegithub.New(t)
+	command := commandtest.NewWithGitHub(t, fake, re.WithGit(git), re.WithOutput(io.Discard))
+	if err := command.SubmitStack(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	// stacked returns the commits of branch on top of base, or nil if base
+	// is not an ancestor of branch.
+	stacked := func(base, branch string) []string {
+		t.Helper()
+		if ok, err := git.IsAncestor(t.Context(), base, branch); err != nil || !ok {
+			return nil
+		}
+		n, err := git.CountCommits(t.Context(), base, branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message, err := git.CommitMessage(t.Context(), branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []string{fmt.Sprint(n), strings.TrimSpace(message)}
+	}
+
+	// A commit added to the parent is rebased onto.
+	if err := git.Switch(t.Context(), "greeting", ""); err != nil {
+		t.Fatal(err)
+	}
+	git.Commit("Greet everyone")
+	if err := git.Switch(t.Context(), "farewell", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.RestackStack(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"1", "Add farewell"}, stacked(git.Branch("greeting"), "farewell")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if current, _ := git.CurrentBranch(t.Context()); current != "farewell" {
+		t.Errorf("got %s checked out, want farewell", current)
+	}
+	if err := command.SubmitStack(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the parent is squash-merged, its commits are dropped, found by
+	// the commit the branch was last rebased onto, and the pull request is
+	// retargeted to the default branch.
+	squashed := git.CommitRemote(origin, "refs/heads/main", "Add greeting (#2)")
+	fake.MergePullRequest(2)
+	if err := command.RestackStack(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"1", "Add farewell"}, stacked(squashed, "farewell")); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if err := command.SubmitStack(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("main", fake.PullRequests()[1].Base); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if diff := cmp.Diff(git.Branch("farewell"), origin.Refs["refs/heads/farewell"]); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
@@ -7,12 +7,18 @@ import (
 	"github.com/konradreiche/re/internal/commandtest/fakegithub"
 )
 
-func New(tb testing.TB) *re.Command {
-	fake := fakegithub.New(tb)
+func New(tb testing.TB, opts ...re.CommandOption) *re.Command {
+	return NewWithGitHub(tb, fakegithub.New(tb), opts...)
+}
+
+// NewWithGitHub returns a command that talks to the given fake GitHub server,
+// for tests that need to control the data it serves.
+func NewWithGitHub(tb testing.TB, fake *fakegithub.FakeGitHub, opts ...re.CommandOption) *re.Command {
 	config := re.Config{
-		Endpoint: fake.URL,
+		Endpoint:     fake.URL,
+		RESTEndpoint: fake.URL,
 	}
-	command, err := re.NewCommand(tb.Context(), config)
+	command, err := re.NewCommand(tb.Context(), config, opts...)
 	if err != nil {
 		tb.Fatal(err)
 	}
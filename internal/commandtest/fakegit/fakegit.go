@@ -0,0 +1,489 @@
+// Package fakegit implements an in-memory [re.Git] for tests.
+package fakegit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	re "github.com/konradreiche/re/internal"
+)
+
+// FakeGit is an in-memory repository. History is linear, every commit has at
+// most one parent. Remotes share the commits of the repository, so a commit
+// only exists on a remote once a ref of the remote points to it.
+type FakeGit struct {
+	// Path is the path of the main worktree.
+	Path string
+
+	commits   map[string]commit
+	branches  map[string]string
+	head      string
+	config    map[string]string
+	remotes   map[string]*Remote
+	hosted    map[string]*Remote
+	tracking  map[string]string
+	fetchHead string
+	worktrees []re.Worktree
+	next      int
+}
+
+type commit struct {
+	parent  string
+	message string
+}
+
+// Remote is a remote of [FakeGit].
+type Remote struct {
+	URL           string
+	DefaultBranch string
+	// Refs maps full ref names, such as refs/heads/main or
+	// refs/pull/1/head, to commits.
+	Refs map[string]string
+	// Pushes are the branches pushed to the remote, in order.
+	Pushes []string
+}
+
+var _ re.Git = (*FakeGit)(nil)
+
+// New returns a repository with main checked out at an initial commit.
+func New() *FakeGit {
+	g := &FakeGit{
+		Path:     "/src/repo",
+		commits:  make(map[string]commit),
+		branches: make(map[string]string),
+		head:     "main",
+		config:   make(map[string]string),
+		remotes:  make(map[string]*Remote),
+		hosted:   make(map[string]*Remote),
+		tracking: make(map[string]string),
+	}
+	g.Commit("Initial commit")
+	return g
+}
+
+// Commit adds a commit with the given message to the checked out branch and
+// returns its hash. The first line of the message is the subject, the body
+// follows after a blank line.
+func (g *FakeGit) Commit(message string) string {
+	return g.CommitBranch(g.head, message)
+}
+
+// CommitBranch adds a commit with the given message to a branch and returns
+// its hash, as if it was committed in the worktree the branch is checked out
+// in.
+func (g *FakeGit) CommitBranch(branch, message string) string {
+	hash := g.commit(g.branches[branch], message)
+	g.branches[branch] = hash
+	return hash
+}
+
+func (g *FakeGit) commit(parent, message string) string {
+	g.next++
+	hash := fmt.Sprintf("%040x", g.next)
+	g.commits[hash] = commit{parent: parent, message: message}
+	return hash
+}
+
+// Branch returns the commit a local branch points to, which is empty if the
+// branch does not exist.
+func (g *FakeGit) Branch(name string) string {
+	return g.branches[name]
+}
+
+// SetRemote adds a remote, whose default branch points to the same commit as
+// the local branch of that name.
+func (g *FakeGit) SetRemote(name, url, defaultBranch string) *Remote {
+	remote := &Remote{
+		URL:           url,
+		DefaultBranch: defaultBranch,
+		Refs:          map[string]string{"refs/heads/" + defaultBranch: g.branches[defaultBranch]},
+	}
+	g.remotes[name] = remote
+	g.tracking[name+"/"+defaultBranch] = g.branches[defaultBranch]
+	return remote
+}
+
+// Hosted returns the repository at a URL, which is what a remote added with
+// [FakeGit.AddRemote] for that URL later on points to.
+func (g *FakeGit) Hosted(url string) *Remote {
+	if _, ok := g.hosted[url]; !ok {
+		g.hosted[url] = &Remote{URL: url, Refs: make(map[string]string)}
+	}
+	return g.hosted[url]
+}
+
+// CommitRemote adds a commit with the given message to a ref of a remote, as
+// if someone else pushed it, and returns its hash. A new ref starts at the
+// default branch of the remote.
+func (g *FakeGit) CommitRemote(remote *Remote, ref, message string) string {
+	parent, ok := remote.Refs[ref]
+	if !ok {
+		parent = remote.Refs["refs/heads/"+remote.DefaultBranch]
+	}
+	hash := g.commit(parent, message)
+	remote.Refs[ref] = hash
+	return hash
+}
+
+// Remote returns a remote, which is nil if it does not exist.
+func (g *FakeGit) Remote(name string) *Remote {
+	return g.remotes[name]
+}
+
+// resolve returns the commit a revision points to. Commit hashes, local and
+// remote-tracking branches with or without their refs/ prefix, HEAD and
+// FETCH_HEAD are supported.
+func (g *FakeGit) resolve(rev string) (string, error) {
+	switch {
+	case rev == "HEAD":
+		rev = g.head
+	case rev == "FETCH_HEAD" && g.fetchHead != "":
+		return g.fetchHead, nil
+	}
+	if _, ok := g.commits[rev]; ok {
+		return rev, nil
+	}
+	if hash, ok := g.branches[strings.TrimPrefix(rev, "refs/heads/")]; ok {
+		return hash, nil
+	}
+	if hash, ok := g.tracking[strings.TrimPrefix(rev, "refs/remotes/")]; ok {
+		return hash, nil
+	}
+	return "", fmt.Errorf("fakegit: unknown revision %q", rev)
+}
+
+// history returns the commits reachable from a commit, starting with the
+// commit itself.
+func (g *FakeGit) history(hash string) []string {
+	var hashes []string
+	for ; hash != ""; hash = g.commits[hash].parent {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+func (g *FakeGit) CurrentBranch(ctx context.Context) (string, error) {
+	return g.head, nil
+}
+
+func (g *FakeGit) CommitMessage(ctx context.Context, rev string) (string, error) {
+	hash, err := g.resolve(rev)
+	if err != nil {
+		return "", err
+	}
+	subject, body, _ := strings.Cut(g.commits[hash].message, "\n")
+	return subject + "\n" + strings.TrimLeft(body, "\n"), nil
+}
+
+func (g *FakeGit) RevParse(ctx context.Context, rev string) (string, error) {
+	return g.resolve(rev)
+}
+
+func (g *FakeGit) IsAncestor(ctx context.Context, ancestor, rev string) (bool, error) {
+	a, err := g.resolve(ancestor)
+	if err != nil {
+		return false, err
+	}
+	b, err := g.resolve(rev)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(g.history(b), a), nil
+}
+
+func (g *FakeGit) MergeBase(ctx context.Context, a, b string) (string, error) {
+	hashA, err := g.resolve(a)
+	if err != nil {
+		return "", err
+	}
+	hashB, err := g.resolve(b)
+	if err != nil {
+		return "", err
+	}
+	historyA := g.history(hashA)
+	for _, hash := range g.history(hashB) {
+		if slices.Contains(historyA, hash) {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("fakegit: no merge base of %s and %s", a, b)
+}
+
+func (g *FakeGit) CountCommits(ctx context.Context, base, rev string) (int, error) {
+	only, err := g.exclusive(base, rev)
+	return len(only), err
+}
+
+// exclusive returns the commits reachable from rev but not from base, from
+// the newest to the oldest.
+func (g *FakeGit) exclusive(base, rev string) ([]string, error) {
+	baseHash, err := g.resolve(base)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := g.resolve(rev)
+	if err != nil {
+		return nil, err
+	}
+	baseHistory := g.history(baseHash)
+	var hashes []string
+	for _, h := range g.history(hash) {
+		if slices.Contains(baseHistory, h) {
+			break
+		}
+		hashes = append(hashes, h)
+	}
+	return hashes, nil
+}
+
+func (g *FakeGit) Branches(ctx context.Context, filter re.BranchFilter) ([]string, error) {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(g.branches)) {
+		ok := true
+		if filter.Merged != "" {
+			merged, err := g.IsAncestor(ctx, name, filter.Merged)
+			if err != nil {
+				return nil, err
+			}
+			ok = ok && merged
+		}
+		if filter.NoMerged != "" {
+			merged, err := g.IsAncestor(ctx, name, filter.NoMerged)
+			if err != nil {
+				return nil, err
+			}
+			ok = ok && !merged
+		}
+		if filter.Contains != "" {
+			contains, err := g.IsAncestor(ctx, filter.Contains, name)
+			if err != nil {
+				return nil, err
+			}
+			ok = ok && contains
+		}
+		if ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (g *FakeGit) Switch(ctx context.Context, branch, startPoint string) error {
+	if startPoint == "" {
+		if _, ok := g.branches[branch]; !ok {
+			return fmt.Errorf("fakegit: no branch %q", branch)
+		}
+		g.head = branch
+		return nil
+	}
+	if _, ok := g.branches[branch]; ok {
+		return fmt.Errorf("fakegit: branch %q already exists", branch)
+	}
+	hash, err := g.resolve(startPoint)
+	if err != nil {
+		return err
+	}
+	g.branches[branch] = hash
+	g.head = branch
+	return nil
+}
+
+func (g *FakeGit) SetBranch(ctx context.Context, branch, commit string) error {
+	if branch == g.head {
+		return fmt.Errorf("fakegit: branch %q is checked out", branch)
+	}
+	hash, err := g.resolve(commit)
+	if err != nil {
+		return err
+	}
+	g.branches[branch] = hash
+	return nil
+}
+
+func (g *FakeGit) DeleteBranch(ctx context.Context, branch string) error {
+	if branch == g.head {
+		return fmt.Errorf("fakegit: branch %q is checked out", branch)
+	}
+	if _, ok := g.branches[branch]; !ok {
+		return fmt.Errorf("fakegit: no branch %q", branch)
+	}
+	delete(g.branches, branch)
+	return nil
+}
+
+func (g *FakeGit) Merge(ctx context.Context, commit string) error {
+	ok, err := g.IsAncestor(ctx, g.head, commit)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("fakegit: not possible to fast-forward")
+	}
+	g.branches[g.head], err = g.resolve(commit)
+	return err
+}
+
+func (g *FakeGit) Rebase(ctx context.Context, onto, upstream, branch string) error {
+	hashes, err := g.exclusive(upstream, branch)
+	if err != nil {
+		return err
+	}
+	tip, err := g.resolve(onto)
+	if err != nil {
+		return err
+	}
+	for _, hash := range slices.Backward(hashes) {
+		tip = g.commit(tip, g.commits[hash].message)
+	}
+	g.branches[branch] = tip
+	g.head = branch
+	return nil
+}
+
+func (g *FakeGit) Config(ctx context.Context, key string) (string, error) {
+	return g.config[key], nil
+}
+
+func (g *FakeGit) SetConfig(ctx context.Context, key, value string) error {
+	g.config[key] = value
+	return nil
+}
+
+func (g *FakeGit) Remotes(ctx context.Context) ([]string, error) {
+	return slices.Sorted(maps.Keys(g.remotes)), nil
+}
+
+// RemoteURL returns the URL of a remote as it was added, there are no
+// url.<base>.insteadOf settings to apply.
+func (g *FakeGit) RemoteURL(ctx context.Context, remote string) (string, error) {
+	r, ok := g.remotes[remote]
+	if !ok {
+		return "", fmt.Errorf("fakegit: no remote named %q", remote)
+	}
+	return r.URL, nil
+}
+
+func (g *FakeGit) AddRemote(ctx context.Context, name, url string) error {
+	if _, ok := g.remotes[name]; ok {
+		return fmt.Errorf("fakegit: remote %q already exists", name)
+	}
+	g.remotes[name] = g.Hosted(url)
+	return nil
+}
+
+func (g *FakeGit) remote(name string) (*Remote, error) {
+	remote, ok := g.remotes[name]
+	if !ok {
+		return nil, fmt.Errorf("fakegit: no remote named %q", name)
+	}
+	return remote, nil
+}
+
+func (g *FakeGit) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	r, err := g.remote(remote)
+	if err != nil {
+		return "", err
+	}
+	if r.DefaultBranch == "" {
+		return "", fmt.Errorf("fakegit: no default branch for %s", remote)
+	}
+	return r.DefaultBranch, nil
+}
+
+func (g *FakeGit) Fetch(ctx context.Context, remote string) error {
+	r, err := g.remote(remote)
+	if err != nil {
+		return err
+	}
+	for ref, hash := range r.Refs {
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			g.tracking[remote+"/"+branch] = hash
+		}
+	}
+	return nil
+}
+
+func (g *FakeGit) FetchRef(ctx context.Context, remote, ref string) (string, error) {
+	r, err := g.remote(remote)
+	if err != nil {
+		return "", err
+	}
+	hash, ok := r.Refs[ref]
+	if !ok {
+		return "", fmt.Errorf("fakegit: couldn't find remote ref %s", ref)
+	}
+	g.fetchHead = hash
+	return hash, nil
+}
+
+func (g *FakeGit) Push(ctx context.Context, remote, branch string, forceWithLease bool) (string, error) {
+	r, err := g.remote(remote)
+	if err != nil {
+		return "", err
+	}
+	hash, ok := g.branches[branch]
+	if !ok {
+		return "", fmt.Errorf("fakegit: no branch %q", branch)
+	}
+	ref := "refs/heads/" + branch
+	if current, ok := r.Refs[ref]; ok {
+		if forceWithLease {
+			if g.tracking[remote+"/"+branch] != current {
+				return "", fmt.Errorf("fakegit: %s on %s changed since it was last fetched", branch, remote)
+			}
+		} else if !slices.Contains(g.history(hash), current) {
+			return "", fmt.Errorf("fakegit: %s on %s is not an ancestor of the pushed commit", branch, remote)
+		}
+	}
+	r.Refs[ref] = hash
+	r.Pushes = append(r.Pushes, branch)
+	g.tracking[remote+"/"+branch] = hash
+	return "", nil
+}
+
+func (g *FakeGit) Worktrees(ctx context.Context) ([]re.Worktree, error) {
+	return append([]re.Worktree{{Path: g.Path, Branch: g.head}}, g.worktrees...), nil
+}
+
+func (g *FakeGit) AddWorktree(ctx context.Context, path, branch, startPoint string) error {
+	worktrees, _ := g.Worktrees(ctx)
+	for _, worktree := range worktrees {
+		if worktree.Path == path {
+			return fmt.Errorf("fakegit: %s already exists", path)
+		}
+		if worktree.Branch == branch {
+			return fmt.Errorf("fakegit: %s is already checked out at %s", branch, worktree.Path)
+		}
+	}
+	if startPoint != "" {
+		if _, ok := g.branches[branch]; ok {
+			return fmt.Errorf("fakegit: branch %q already exists", branch)
+		}
+		hash, err := g.resolve(startPoint)
+		if err != nil {
+			return err
+		}
+		g.branches[branch] = hash
+	} else if _, ok := g.branches[branch]; !ok {
+		return fmt.Errorf("fakegit: no branch %q", branch)
+	}
+	g.worktrees = append(g.worktrees, re.Worktree{Path: path, Branch: branch})
+	return nil
+}
+
+func (g *FakeGit) RemoveWorktree(ctx context.Context, path string) error {
+	i := slices.IndexFunc(g.worktrees, func(w re.Worktree) bool { return w.Path == path })
+	if i < 0 {
+		return fmt.Errorf("fakegit: %s is not a worktree", path)
+	}
+	g.worktrees = slices.Delete(g.worktrees, i, i+1)
+	return nil
+}
+
+func (g *FakeGit) PruneWorktrees(ctx context.Context) error {
+	return nil
+}
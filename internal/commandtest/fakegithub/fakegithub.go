@@ -1,8 +1,14 @@
 package fakegithub
 
 import (
+	"cmp"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/99designs/gqlgen/graphql/handler"
@@ -16,29 +22,425 @@ import (
 
 type FakeGitHub struct {
 	URL string
+
+	mux      *http.ServeMux
+	resolver *graph.Resolver
+	mu       sync.Mutex
+	created  []CreatedPullRequest
+	pulls    []*PullRequest
+	reruns   []string
+}
+
+// CreatedPullRequest is a pull request created through the REST API.
+type CreatedPullRequest struct {
+	Owner          string
+	Name           string
+	Title          string `json:"title"`
+	Head           string `json:"head"`
+	HeadRepository string `json:"head_repo"`
+	Base           string `json:"base"`
+	Body           string `json:"body"`
+	Draft          bool   `json:"draft"`
+}
+
+// CreatedPullRequests returns the pull requests created so far.
+func (f *FakeGitHub) CreatedPullRequests() []CreatedPullRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.created)
+}
+
+// createPullRequest serves POST /repos/{owner}/{name}/pulls. The created pull
+// requests are numbered after the one the test repository always contains.
+func (f *FakeGitHub) createPullRequest(w http.ResponseWriter, r *http.Request) {
+	var pr CreatedPullRequest
+	if err := json.NewDecoder(r.Body).Decode(&pr); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	pr.Owner, pr.Name = r.PathValue("owner"), r.PathValue("name")
+	f.mu.Lock()
+	f.created = append(f.created, pr)
+	number := len(f.created) + 1
+	f.pulls = append(f.pulls, &PullRequest{
+		Number: number,
+		Head:   pr.Head,
+		Base:   pr.Base,
+		Title:  pr.Title,
+		Body:   pr.Body,
+		Draft:  pr.Draft,
+	})
+	f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"number": %d}`, number)
+}
+
+// PullRequest is the current state of a pull request created through the REST
+// API.
+type PullRequest struct {
+	Number int
+	// Head is the head branch, prefixed with the owner of the fork and a
+	// colon if it is in a fork.
+	Head   string
+	Base   string
+	Title  string
+	Body   string
+	Draft  bool
+	Merged bool
+}
+
+// PullRequests returns the pull requests created through the REST API.
+func (f *FakeGitHub) PullRequests() []PullRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pulls := make([]PullRequest, len(f.pulls))
+	for i, pr := range f.pulls {
+		pulls[i] = *pr
+	}
+	return pulls
+}
+
+// MergePullRequest marks a pull request created through the REST API as
+// merged, as if it was merged on GitHub.
+func (f *FakeGitHub) MergePullRequest(number int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if pr := f.pull(number); pr != nil {
+		pr.Merged = true
+	}
+}
+
+// pull returns the pull request with the given number, or nil if there is
+// none. f.mu must be held.
+func (f *FakeGitHub) pull(number int) *PullRequest {
+	i := slices.IndexFunc(f.pulls, func(pr *PullRequest) bool { return pr.Number == number })
+	if i < 0 {
+		return nil
+	}
+	return f.pulls[i]
+}
+
+// listPullRequests serves GET /repos/{owner}/{name}/pulls, filtered by the
+// head query parameter given as owner:branch.
+func (f *FakeGitHub) listPullRequests(w http.ResponseWriter, r *http.Request) {
+	head := r.URL.Query().Get("head")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pulls := []map[string]any{}
+	for _, pr := range f.pulls {
+		if head != "" && head != pr.Head && head != r.PathValue("owner")+":"+pr.Head {
+			continue
+		}
+		state, mergedAt := "open", any(nil)
+		if pr.Merged {
+			state, mergedAt = "closed", "2006-01-02T15:04:05Z"
+		}
+		pulls = append(pulls, map[string]any{
+			"number":    pr.Number,
+			"title":     pr.Title,
+			"body":      pr.Body,
+			"state":     state,
+			"draft":     pr.Draft,
+			"merged_at": mergedAt,
+			"base":      map[string]string{"ref": pr.Base},
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pulls)
+}
+
+// updatePullRequest serves PATCH /repos/{owner}/{name}/pulls/{number}, which
+// changes the base and body of a pull request.
+func (f *FakeGitHub) updatePullRequest(w http.ResponseWriter, r *http.Request) {
+	var update struct {
+		Base string `json:"base"`
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr := f.pull(number)
+	if pr == nil {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+		return
+	}
+	pr.Base = cmp.Or(update.Base, pr.Base)
+	pr.Body = cmp.Or(update.Body, pr.Body)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"number": %d}`, number)
+}
+
+// listFiles serves GET /repos/{owner}/{name}/pulls/{number}/files with a page
+// size of per_page and a Link header to the next page, like the REST API.
+// Every file adds a single line.
+func (f *FakeGitHub) listFiles(w http.ResponseWriter, r *http.Request) {
+	perPage, err := strconv.Atoi(cmp.Or(r.URL.Query().Get("per_page"), "30"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := strconv.Atoi(cmp.Or(r.URL.Query().Get("page"), "1"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	start := min((page-1)*perPage, len(f.resolver.Files))
+	end := min(start+perPage, len(f.resolver.Files))
+	if end < len(f.resolver.Files) {
+		next := *r.URL
+		next.Scheme, next.Host = "http", r.Host
+		query := next.Query()
+		query.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+	files := make([]map[string]any, 0, end-start)
+	for _, name := range f.resolver.Files[start:end] {
+		files = append(files, map[string]any{
+			"filename": name,
+			"status":   "added",
+			"patch":    "@@ -0,0 +1 @@\n+hello",
+			"changes":  1,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(files)
+}
+
+// HandleFunc registers a handler for a REST endpoint, for tests that need to
+// control responses the fake does not serve by itself.
+func (f *FakeGitHub) HandleFunc(pattern string, handler http.HandlerFunc) {
+	f.mux.HandleFunc(pattern, handler)
+}
+
+// Reruns returns the request paths of the workflow runs re-run so far.
+func (f *FakeGitHub) Reruns() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.reruns)
+}
+
+// rerunWorkflowRun serves POST /repos/{owner}/{name}/actions/runs/{id}/rerun
+// and rerun-failed-jobs.
+func (f *FakeGitHub) rerunWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.reruns = append(f.reruns, r.URL.Path)
+	f.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Option configures the data served by [FakeGitHub].
+type Option func(*graph.Resolver)
+
+// WithPullRequests adds n open pull requests to the test repository, in
+// addition to the one it always contains.
+func WithPullRequests(n int) Option {
+	return func(r *graph.Resolver) {
+		edges := r.Repo.PullRequests.Edges
+		for i := range n {
+			number := int32(len(edges) + i + 1)
+			edges = append(edges, &model.PullRequestEdge{
+				Node: &model.PullRequest{
+					ID:               fmt.Sprint(number),
+					Number:           number,
+					Title:            fmt.Sprintf("Pull request %d", number),
+					State:            model.PullRequestStateOpen,
+					Mergeable:        model.MergeableStateMergeable,
+					MergeStateStatus: model.MergeStateStatusClean,
+					Author:           &model.User{Login: "bar"},
+					CreatedAt:        "2006-01-02T15:04:05Z",
+					Comments:         &model.IssueCommentConnection{},
+					Commits: &model.PullRequestCommitConnection{
+						Nodes: []*model.PullRequestCommit{},
+					},
+					Repository: &model.Repository{Name: "test-repo"},
+					Reviews: &model.PullRequestReviewConnection{
+						Edges: []*model.PullRequestReviewEdge{},
+					},
+				},
+			})
+		}
+		r.Repo.PullRequests.Edges = edges
+	}
+}
+
+// WithReviews adds n reviews to the pull request the test repository always
+// contains, with the database IDs 1 to n.
+func WithReviews(n int) Option {
+	return func(r *graph.Resolver) {
+		reviews := r.Repo.PullRequests.Edges[0].Node.Reviews
+		for i := range n {
+			id := int32(i + 1)
+			reviews.Edges = append(reviews.Edges, &model.PullRequestReviewEdge{
+				Node: &model.PullRequestReview{
+					ID:         fmt.Sprintf("PRR_%d", id),
+					DatabaseID: &id,
+					Author:     &model.User{Login: "bar"},
+					Comments:   &model.PullRequestReviewCommentConnection{},
+					CreatedAt:  "2006-01-02T15:04:05Z",
+					State:      model.PullRequestReviewStateCommented,
+				},
+			})
+		}
+	}
+}
+
+// WithReview adds a review with the given state and body to the pull request
+// the test repository always contains, numbered after those added before.
+func WithReview(state model.PullRequestReviewState, body string) Option {
+	return func(r *graph.Resolver) {
+		reviews := r.Repo.PullRequests.Edges[0].Node.Reviews
+		id := int32(len(reviews.Edges) + 1)
+		reviews.Edges = append(reviews.Edges, &model.PullRequestReviewEdge{
+			Node: &model.PullRequestReview{
+				ID:         fmt.Sprintf("PRR_%d", id),
+				DatabaseID: &id,
+				Author:     &model.User{Login: "bar"},
+				Body:       body,
+				Comments:   &model.PullRequestReviewCommentConnection{},
+				CreatedAt:  "2006-01-02T15:04:05Z",
+				State:      state,
+			},
+		})
+	}
+}
+
+// ReviewState returns the state of the review with the given database ID.
+func (f *FakeGitHub) ReviewState(id int) model.PullRequestReviewState {
+	return f.resolver.ReviewState(int32(id))
+}
+
+// WithHead sets the head branch of the pull request the test repository
+// always contains. Unless owner is empty, the branch is in a fork of the test
+// repository owned by owner.
+func WithHead(ref, owner string, maintainerCanModify bool) Option {
+	return func(r *graph.Resolver) {
+		pr := r.Repo.PullRequests.Edges[0].Node
+		pr.HeadRef = &model.Ref{Name: ref}
+		pr.HeadRefName = ref
+		if owner == "" {
+			return
+		}
+		pr.IsCrossRepository = true
+		pr.MaintainerCanModify = maintainerCanModify
+		pr.HeadRepository = &model.Repository{
+			ID:     "2",
+			Name:   r.Repo.Name,
+			Owner:  &model.User{Login: owner},
+			URL:    "https://github.com/" + owner + "/" + r.Repo.Name,
+			SSHURL: "git@github.com:" + owner + "/" + r.Repo.Name + ".git",
+		}
+	}
+}
+
+// WithState sets the state of the pull request the test repository always
+// contains.
+func WithState(state model.PullRequestState) Option {
+	return func(r *graph.Resolver) {
+		r.Repo.PullRequests.Edges[0].Node.State = state
+	}
+}
+
+// WithFiles adds n files to the changes of the pull requests.
+func WithFiles(n int) Option {
+	return func(r *graph.Resolver) {
+		for i := range n {
+			r.Files = append(r.Files, fmt.Sprintf("file%d.go", i+1))
+		}
+	}
+}
+
+// WithCheckRun adds a check run to the head commit of the pull request the
+// test repository always contains. It is in progress for the first pending
+// requests of the checks and completes with the given conclusion after that,
+// or never if the conclusion is empty.
+func WithCheckRun(name string, pending int, conclusion model.CheckConclusionState) Option {
+	return func(r *graph.Resolver) {
+		pr := r.Repo.PullRequests.Edges[0].Node
+		pr.Commits.Nodes = []*model.PullRequestCommit{
+			{Commit: &model.Commit{ID: pr.HeadRefOid, Oid: pr.HeadRefOid}},
+		}
+		id := int32(1)
+		suites := func(run *model.CheckRun) *model.CheckSuiteConnection {
+			return &model.CheckSuiteConnection{
+				Nodes: []*model.CheckSuite{
+					{
+						ID:         "1",
+						DatabaseID: &id,
+						App:        &model.App{ID: "1", Name: "GitHub Actions"},
+						WorkflowRun: &model.WorkflowRun{
+							ID:         "1",
+							DatabaseID: &id,
+							Workflow:   &model.Workflow{ID: "1", Name: "CI"},
+						},
+						CheckRuns: &model.CheckRunConnection{Nodes: []*model.CheckRun{run}, TotalCount: 1},
+					},
+				},
+				TotalCount: 1,
+			}
+		}
+		for range pending {
+			r.CheckSuites = append(r.CheckSuites, suites(&model.CheckRun{
+				ID:         name,
+				DatabaseID: &id,
+				Name:       name,
+				Status:     model.CheckStatusStateInProgress,
+			}))
+		}
+		if conclusion != "" {
+			r.CheckSuites = append(r.CheckSuites, suites(&model.CheckRun{
+				ID:         name,
+				DatabaseID: &id,
+				Name:       name,
+				Status:     model.CheckStatusStateCompleted,
+				Conclusion: &conclusion,
+			}))
+		}
+	}
 }
 
-func New(tb testing.TB) *FakeGitHub {
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{
+func New(tb testing.TB, opts ...Option) *FakeGitHub {
+	resolver := &graph.Resolver{
 		Viewer: &model.User{
 			Login: "foo",
 		},
-		Repository: &model.Repository{
-			ID:   "1",
-			Name: "test-repo",
+		Repo: &model.Repository{
+			ID:     "1",
+			Name:   "test-repo",
+			Owner:  &model.User{Login: "foo"},
+			URL:    "https://github.com/foo/test-repo",
+			SSHURL: "git@github.com:foo/test-repo.git",
 			PullRequests: &model.PullRequestConnection{
 				Edges: []*model.PullRequestEdge{
 					{
 						Node: &model.PullRequest{
-							ID:     "1",
-							Author: &model.User{Login: "foo"},
+							ID:               "1",
+							Number:           1,
+							Author:           &model.User{Login: "foo"},
+							State:            model.PullRequestStateOpen,
+							Mergeable:        model.MergeableStateMergeable,
+							MergeStateStatus: model.MergeStateStatusClean,
+							HeadRefOid:       "c0ffee",
 							HeadRef: &model.Ref{
 								Name: "main",
 							},
-							CreatedAt: "2006-01-02T15:04:05Z",
+							HeadRefName: "main",
+							CreatedAt:   "2006-01-02T15:04:05Z",
 							Comments: &model.IssueCommentConnection{
 								TotalCount: 1,
 							},
+							Commits: &model.PullRequestCommitConnection{
+								Nodes: []*model.PullRequestCommit{},
+							},
 							Repository: &model.Repository{Name: "test-repo"},
 							Reviews: &model.PullRequestReviewConnection{
 								Edges: []*model.PullRequestReviewEdge{},
@@ -48,7 +450,12 @@ func New(tb testing.TB) *FakeGitHub {
 				},
 			},
 		},
-	}}))
+	}
+	resolver.Repo.PullRequests.Edges[0].Node.HeadRepository = resolver.Repo
+	for _, opt := range opts {
+		opt(resolver)
+	}
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -60,12 +467,18 @@ func New(tb testing.TB) *FakeGitHub {
 		Cache: lru.New[string](100),
 	})
 
-	ts := httptest.NewServer(srv)
-	tb.Cleanup(ts.Close)
+	mux := http.NewServeMux()
+	fake := &FakeGitHub{mux: mux, resolver: resolver}
+	mux.Handle("/", srv)
+	mux.HandleFunc("POST /repos/{owner}/{name}/pulls", fake.createPullRequest)
+	mux.HandleFunc("GET /repos/{owner}/{name}/pulls", fake.listPullRequests)
+	mux.HandleFunc("PATCH /repos/{owner}/{name}/pulls/{number}", fake.updatePullRequest)
+	mux.HandleFunc("POST /repos/{owner}/{name}/actions/runs/{id}/{action}", fake.rerunWorkflowRun)
+	mux.HandleFunc("GET /repos/{owner}/{name}/pulls/{number}/files", fake.listFiles)
 
-	http.Handle("/graphql/query", srv)
+	ts := httptest.NewServer(mux)
+	tb.Cleanup(ts.Close)
 
-	return &FakeGitHub{
-		URL: ts.URL,
-	}
+	fake.URL = ts.URL
+	return fake
 }
@@ -19,10 +19,86 @@ type Node interface {
 	GetID() string
 }
 
+type RepositoryOwner interface {
+	IsRepositoryOwner()
+	GetLogin() string
+}
+
 type SearchResultItem interface {
 	IsSearchResultItem()
 }
 
+type StatusCheckRollupContext interface {
+	IsStatusCheckRollupContext()
+}
+
+type App struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (App) IsNode()            {}
+func (this App) GetID() string { return this.ID }
+
+type CheckRun struct {
+	ID          string                `json:"id"`
+	DatabaseID  *int32                `json:"databaseId,omitempty"`
+	Name        string                `json:"name"`
+	Status      CheckStatusState      `json:"status"`
+	Conclusion  *CheckConclusionState `json:"conclusion,omitempty"`
+	StartedAt   *string               `json:"startedAt,omitempty"`
+	CompletedAt *string               `json:"completedAt,omitempty"`
+	DetailsURL  *string               `json:"detailsUrl,omitempty"`
+}
+
+func (CheckRun) IsStatusCheckRollupContext() {}
+
+func (CheckRun) IsNode()            {}
+func (this CheckRun) GetID() string { return this.ID }
+
+type CheckRunConnection struct {
+	Nodes      []*CheckRun `json:"nodes,omitempty"`
+	TotalCount int32       `json:"totalCount"`
+}
+
+type CheckSuite struct {
+	ID          string              `json:"id"`
+	DatabaseID  *int32              `json:"databaseId,omitempty"`
+	App         *App                `json:"app,omitempty"`
+	WorkflowRun *WorkflowRun        `json:"workflowRun,omitempty"`
+	CheckRuns   *CheckRunConnection `json:"checkRuns,omitempty"`
+}
+
+func (CheckSuite) IsNode()            {}
+func (this CheckSuite) GetID() string { return this.ID }
+
+type CheckSuiteConnection struct {
+	Nodes      []*CheckSuite `json:"nodes,omitempty"`
+	TotalCount int32         `json:"totalCount"`
+}
+
+type Commit struct {
+	ID                string                `json:"id"`
+	Oid               string                `json:"oid"`
+	Status            *Status               `json:"status,omitempty"`
+	StatusCheckRollup *StatusCheckRollup    `json:"statusCheckRollup,omitempty"`
+	CheckSuites       *CheckSuiteConnection `json:"checkSuites,omitempty"`
+}
+
+func (Commit) IsNode()            {}
+func (this Commit) GetID() string { return this.ID }
+
+type DismissPullRequestReviewInput struct {
+	ClientMutationID    *string `json:"clientMutationId,omitempty"`
+	Message             string  `json:"message"`
+	PullRequestReviewID string  `json:"pullRequestReviewId"`
+}
+
+type DismissPullRequestReviewPayload struct {
+	ClientMutationID  *string            `json:"clientMutationId,omitempty"`
+	PullRequestReview *PullRequestReview `json:"pullRequestReview,omitempty"`
+}
+
 type IssueCommentConnection struct {
 	TotalCount int32 `json:"totalCount"`
 }
@@ -37,17 +113,38 @@ type IssueOrder struct {
 	Field     IssueOrderField `json:"field"`
 }
 
+type Mutation struct {
+}
+
+type PageInfo struct {
+	EndCursor       *string `json:"endCursor,omitempty"`
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+}
+
 type PullRequest struct {
-	ID         string                       `json:"id"`
-	Author     Actor                        `json:"author,omitempty"`
-	BaseRefOid string                       `json:"baseRefOid"`
-	Number     int32                        `json:"number"`
-	Title      string                       `json:"title"`
-	CreatedAt  string                       `json:"createdAt"`
-	HeadRef    *Ref                         `json:"headRef,omitempty"`
-	Comments   *IssueCommentConnection      `json:"comments"`
-	Repository *Repository                  `json:"repository"`
-	Reviews    *PullRequestReviewConnection `json:"reviews,omitempty"`
+	ID                  string                       `json:"id"`
+	Author              Actor                        `json:"author,omitempty"`
+	BaseRefOid          string                       `json:"baseRefOid"`
+	Number              int32                        `json:"number"`
+	Title               string                       `json:"title"`
+	State               PullRequestState             `json:"state"`
+	IsDraft             bool                         `json:"isDraft"`
+	Mergeable           MergeableState               `json:"mergeable"`
+	MergeStateStatus    MergeStateStatus             `json:"mergeStateStatus"`
+	ReviewDecision      *PullRequestReviewDecision   `json:"reviewDecision,omitempty"`
+	CreatedAt           string                       `json:"createdAt"`
+	HeadRef             *Ref                         `json:"headRef,omitempty"`
+	HeadRefName         string                       `json:"headRefName"`
+	HeadRefOid          string                       `json:"headRefOid"`
+	HeadRepository      *Repository                  `json:"headRepository,omitempty"`
+	IsCrossRepository   bool                         `json:"isCrossRepository"`
+	MaintainerCanModify bool                         `json:"maintainerCanModify"`
+	Commits             *PullRequestCommitConnection `json:"commits"`
+	Comments            *IssueCommentConnection      `json:"comments"`
+	Repository          *Repository                  `json:"repository"`
+	Reviews             *PullRequestReviewConnection `json:"reviews,omitempty"`
 }
 
 func (PullRequest) IsNode()            {}
@@ -55,9 +152,19 @@ func (this PullRequest) GetID() string { return this.ID }
 
 func (PullRequest) IsSearchResultItem() {}
 
+type PullRequestCommit struct {
+	Commit *Commit `json:"commit"`
+}
+
+type PullRequestCommitConnection struct {
+	Nodes      []*PullRequestCommit `json:"nodes,omitempty"`
+	TotalCount int32                `json:"totalCount"`
+}
+
 type PullRequestConnection struct {
 	Edges      []*PullRequestEdge `json:"edges,omitempty"`
 	Nodes      []*PullRequest     `json:"nodes,omitempty"`
+	PageInfo   *PageInfo          `json:"pageInfo"`
 	TotalCount int32              `json:"totalCount"`
 }
 
@@ -67,11 +174,13 @@ type PullRequestEdge struct {
 }
 
 type PullRequestReview struct {
-	Author    Actor                               `json:"author,omitempty"`
-	Body      string                              `json:"body"`
-	Comments  *PullRequestReviewCommentConnection `json:"comments"`
-	CreatedAt string                              `json:"createdAt"`
-	ID        string                              `json:"id"`
+	Author     Actor                               `json:"author,omitempty"`
+	Body       string                              `json:"body"`
+	DatabaseID *int32                              `json:"databaseId,omitempty"`
+	Comments   *PullRequestReviewCommentConnection `json:"comments"`
+	CreatedAt  string                              `json:"createdAt"`
+	ID         string                              `json:"id"`
+	State      PullRequestReviewState              `json:"state"`
 }
 
 func (PullRequestReview) IsNode()            {}
@@ -94,7 +203,9 @@ type PullRequestReviewCommentEdge struct {
 }
 
 type PullRequestReviewConnection struct {
-	Edges []*PullRequestReviewEdge `json:"edges,omitempty"`
+	Edges      []*PullRequestReviewEdge `json:"edges,omitempty"`
+	PageInfo   *PageInfo                `json:"pageInfo"`
+	TotalCount int32                    `json:"totalCount"`
 }
 
 type PullRequestReviewEdge struct {
@@ -104,6 +215,13 @@ type PullRequestReviewEdge struct {
 type Query struct {
 }
 
+type RateLimit struct {
+	Cost      int32  `json:"cost"`
+	Limit     int32  `json:"limit"`
+	Remaining int32  `json:"remaining"`
+	ResetAt   string `json:"resetAt"`
+}
+
 type Ref struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -115,6 +233,10 @@ func (this Ref) GetID() string { return this.ID }
 type Repository struct {
 	ID           string                 `json:"id"`
 	Name         string                 `json:"name"`
+	Owner        RepositoryOwner        `json:"owner"`
+	URL          string                 `json:"url"`
+	SSHURL       string                 `json:"sshUrl"`
+	PullRequest  *PullRequest           `json:"pullRequest,omitempty"`
 	PullRequests *PullRequestConnection `json:"pullRequests"`
 }
 
@@ -131,6 +253,36 @@ type SearchResultItemEdge struct {
 	Node SearchResultItem `json:"node,omitempty"`
 }
 
+type Status struct {
+	State    StatusState      `json:"state"`
+	Contexts []*StatusContext `json:"contexts"`
+}
+
+type StatusCheckRollup struct {
+	ID       string                              `json:"id"`
+	State    StatusState                         `json:"state"`
+	Contexts *StatusCheckRollupContextConnection `json:"contexts"`
+}
+
+func (StatusCheckRollup) IsNode()            {}
+func (this StatusCheckRollup) GetID() string { return this.ID }
+
+type StatusCheckRollupContextConnection struct {
+	Nodes      []StatusCheckRollupContext `json:"nodes,omitempty"`
+	PageInfo   *PageInfo                  `json:"pageInfo"`
+	TotalCount int32                      `json:"totalCount"`
+}
+
+type StatusContext struct {
+	Context     string      `json:"context"`
+	CreatedAt   string      `json:"createdAt"`
+	Description *string     `json:"description,omitempty"`
+	State       StatusState `json:"state"`
+	TargetURL   *string     `json:"targetUrl,omitempty"`
+}
+
+func (StatusContext) IsStatusCheckRollupContext() {}
+
 type User struct {
 	Login string  `json:"login"`
 	Name  *string `json:"name,omitempty"`
@@ -139,8 +291,159 @@ type User struct {
 func (User) IsActor()              {}
 func (this User) GetLogin() string { return this.Login }
 
+func (User) IsRepositoryOwner() {}
+
 func (User) IsSearchResultItem() {}
 
+type Workflow struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (Workflow) IsNode()            {}
+func (this Workflow) GetID() string { return this.ID }
+
+type WorkflowRun struct {
+	ID         string    `json:"id"`
+	DatabaseID *int32    `json:"databaseId,omitempty"`
+	Workflow   *Workflow `json:"workflow"`
+}
+
+func (WorkflowRun) IsNode()            {}
+func (this WorkflowRun) GetID() string { return this.ID }
+
+type CheckConclusionState string
+
+const (
+	CheckConclusionStateActionRequired CheckConclusionState = "ACTION_REQUIRED"
+	CheckConclusionStateCancelled      CheckConclusionState = "CANCELLED"
+	CheckConclusionStateFailure        CheckConclusionState = "FAILURE"
+	CheckConclusionStateNeutral        CheckConclusionState = "NEUTRAL"
+	CheckConclusionStateSkipped        CheckConclusionState = "SKIPPED"
+	CheckConclusionStateStale          CheckConclusionState = "STALE"
+	CheckConclusionStateStartupFailure CheckConclusionState = "STARTUP_FAILURE"
+	CheckConclusionStateSuccess        CheckConclusionState = "SUCCESS"
+	CheckConclusionStateTimedOut       CheckConclusionState = "TIMED_OUT"
+)
+
+var AllCheckConclusionState = []CheckConclusionState{
+	CheckConclusionStateActionRequired,
+	CheckConclusionStateCancelled,
+	CheckConclusionStateFailure,
+	CheckConclusionStateNeutral,
+	CheckConclusionStateSkipped,
+	CheckConclusionStateStale,
+	CheckConclusionStateStartupFailure,
+	CheckConclusionStateSuccess,
+	CheckConclusionStateTimedOut,
+}
+
+func (e CheckConclusionState) IsValid() bool {
+	switch e {
+	case CheckConclusionStateActionRequired, CheckConclusionStateCancelled, CheckConclusionStateFailure, CheckConclusionStateNeutral, CheckConclusionStateSkipped, CheckConclusionStateStale, CheckConclusionStateStartupFailure, CheckConclusionStateSuccess, CheckConclusionStateTimedOut:
+		return true
+	}
+	return false
+}
+
+func (e CheckConclusionState) String() string {
+	return string(e)
+}
+
+func (e *CheckConclusionState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CheckConclusionState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CheckConclusionState", str)
+	}
+	return nil
+}
+
+func (e CheckConclusionState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CheckConclusionState) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CheckConclusionState) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type CheckStatusState string
+
+const (
+	CheckStatusStateCompleted  CheckStatusState = "COMPLETED"
+	CheckStatusStateInProgress CheckStatusState = "IN_PROGRESS"
+	CheckStatusStatePending    CheckStatusState = "PENDING"
+	CheckStatusStateQueued     CheckStatusState = "QUEUED"
+	CheckStatusStateRequested  CheckStatusState = "REQUESTED"
+	CheckStatusStateWaiting    CheckStatusState = "WAITING"
+)
+
+var AllCheckStatusState = []CheckStatusState{
+	CheckStatusStateCompleted,
+	CheckStatusStateInProgress,
+	CheckStatusStatePending,
+	CheckStatusStateQueued,
+	CheckStatusStateRequested,
+	CheckStatusStateWaiting,
+}
+
+func (e CheckStatusState) IsValid() bool {
+	switch e {
+	case CheckStatusStateCompleted, CheckStatusStateInProgress, CheckStatusStatePending, CheckStatusStateQueued, CheckStatusStateRequested, CheckStatusStateWaiting:
+		return true
+	}
+	return false
+}
+
+func (e CheckStatusState) String() string {
+	return string(e)
+}
+
+func (e *CheckStatusState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CheckStatusState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CheckStatusState", str)
+	}
+	return nil
+}
+
+func (e CheckStatusState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CheckStatusState) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CheckStatusState) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type IssueCommentOrderField string
 
 const (
@@ -251,6 +554,130 @@ func (e IssueOrderField) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type MergeStateStatus string
+
+const (
+	MergeStateStatusBehind   MergeStateStatus = "BEHIND"
+	MergeStateStatusBlocked  MergeStateStatus = "BLOCKED"
+	MergeStateStatusClean    MergeStateStatus = "CLEAN"
+	MergeStateStatusDirty    MergeStateStatus = "DIRTY"
+	MergeStateStatusDraft    MergeStateStatus = "DRAFT"
+	MergeStateStatusHasHooks MergeStateStatus = "HAS_HOOKS"
+	MergeStateStatusUnknown  MergeStateStatus = "UNKNOWN"
+	MergeStateStatusUnstable MergeStateStatus = "UNSTABLE"
+)
+
+var AllMergeStateStatus = []MergeStateStatus{
+	MergeStateStatusBehind,
+	MergeStateStatusBlocked,
+	MergeStateStatusClean,
+	MergeStateStatusDirty,
+	MergeStateStatusDraft,
+	MergeStateStatusHasHooks,
+	MergeStateStatusUnknown,
+	MergeStateStatusUnstable,
+}
+
+func (e MergeStateStatus) IsValid() bool {
+	switch e {
+	case MergeStateStatusBehind, MergeStateStatusBlocked, MergeStateStatusClean, MergeStateStatusDirty, MergeStateStatusDraft, MergeStateStatusHasHooks, MergeStateStatusUnknown, MergeStateStatusUnstable:
+		return true
+	}
+	return false
+}
+
+func (e MergeStateStatus) String() string {
+	return string(e)
+}
+
+func (e *MergeStateStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MergeStateStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MergeStateStatus", str)
+	}
+	return nil
+}
+
+func (e MergeStateStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MergeStateStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MergeStateStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MergeableState string
+
+const (
+	MergeableStateConflicting MergeableState = "CONFLICTING"
+	MergeableStateMergeable   MergeableState = "MERGEABLE"
+	MergeableStateUnknown     MergeableState = "UNKNOWN"
+)
+
+var AllMergeableState = []MergeableState{
+	MergeableStateConflicting,
+	MergeableStateMergeable,
+	MergeableStateUnknown,
+}
+
+func (e MergeableState) IsValid() bool {
+	switch e {
+	case MergeableStateConflicting, MergeableStateMergeable, MergeableStateUnknown:
+		return true
+	}
+	return false
+}
+
+func (e MergeableState) String() string {
+	return string(e)
+}
+
+func (e *MergeableState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MergeableState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MergeableState", str)
+	}
+	return nil
+}
+
+func (e MergeableState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MergeableState) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MergeableState) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type OrderDirection string
 
 const (
@@ -306,6 +733,63 @@ func (e OrderDirection) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type PullRequestReviewDecision string
+
+const (
+	PullRequestReviewDecisionApproved         PullRequestReviewDecision = "APPROVED"
+	PullRequestReviewDecisionChangesRequested PullRequestReviewDecision = "CHANGES_REQUESTED"
+	PullRequestReviewDecisionReviewRequired   PullRequestReviewDecision = "REVIEW_REQUIRED"
+)
+
+var AllPullRequestReviewDecision = []PullRequestReviewDecision{
+	PullRequestReviewDecisionApproved,
+	PullRequestReviewDecisionChangesRequested,
+	PullRequestReviewDecisionReviewRequired,
+}
+
+func (e PullRequestReviewDecision) IsValid() bool {
+	switch e {
+	case PullRequestReviewDecisionApproved, PullRequestReviewDecisionChangesRequested, PullRequestReviewDecisionReviewRequired:
+		return true
+	}
+	return false
+}
+
+func (e PullRequestReviewDecision) String() string {
+	return string(e)
+}
+
+func (e *PullRequestReviewDecision) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PullRequestReviewDecision(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PullRequestReviewDecision", str)
+	}
+	return nil
+}
+
+func (e PullRequestReviewDecision) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PullRequestReviewDecision) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PullRequestReviewDecision) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type PullRequestReviewState string
 
 const (
@@ -482,3 +966,64 @@ func (e SearchType) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type StatusState string
+
+const (
+	StatusStateError    StatusState = "ERROR"
+	StatusStateExpected StatusState = "EXPECTED"
+	StatusStateFailure  StatusState = "FAILURE"
+	StatusStatePending  StatusState = "PENDING"
+	StatusStateSuccess  StatusState = "SUCCESS"
+)
+
+var AllStatusState = []StatusState{
+	StatusStateError,
+	StatusStateExpected,
+	StatusStateFailure,
+	StatusStatePending,
+	StatusStateSuccess,
+}
+
+func (e StatusState) IsValid() bool {
+	switch e {
+	case StatusStateError, StatusStateExpected, StatusStateFailure, StatusStatePending, StatusStateSuccess:
+		return true
+	}
+	return false
+}
+
+func (e StatusState) String() string {
+	return string(e)
+}
+
+func (e *StatusState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StatusState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StatusState", str)
+	}
+	return nil
+}
+
+func (e StatusState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *StatusState) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e StatusState) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -1,11 +1,64 @@
 package graph
 
-import "github.com/konradreiche/re/internal/commandtest/fakegithub/graph/model"
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/konradreiche/re/internal/commandtest/fakegithub/graph/model"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 type Resolver struct {
-	Viewer     *model.User
-	Repository *model.Repository
+	Viewer *model.User
+	Repo   *model.Repository
+	// CheckSuites are served in turn as the check suites of a commit, one
+	// per request, and the last one for all requests after that. The check
+	// suites of the commit itself are served if it is empty.
+	CheckSuites []*model.CheckSuiteConnection
+	// Files are the names of the files changed by the pull requests, which
+	// are served by the REST API rather than through GraphQL.
+	Files []string
+
+	mu sync.Mutex
+}
+
+// ReviewState returns the state of the review with the given database ID on
+// the first pull request, which mutations may have changed.
+func (r *Resolver) ReviewState(id int32) model.PullRequestReviewState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, edge := range r.Repo.PullRequests.Edges[0].Node.Reviews.Edges {
+		if review := edge.Node; review.DatabaseID != nil && *review.DatabaseID == id {
+			return review.State
+		}
+	}
+	return ""
+}
+
+// paginate returns the range of the page of a list of the given length that
+// starts after the cursor, which is the index of the last item of the previous
+// page.
+func paginate(length int, after *string, first *int32) (int, int, *model.PageInfo, error) {
+	start := 0
+	if after != nil {
+		i, err := strconv.Atoi(*after)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid cursor: %s", *after)
+		}
+		start = i + 1
+	}
+	end := length
+	if first != nil {
+		end = min(end, start+int(*first))
+	}
+	start = min(start, end)
+	pageInfo := &model.PageInfo{HasNextPage: end < length}
+	if end > start {
+		cursor := strconv.Itoa(end - 1)
+		pageInfo.EndCursor = &cursor
+	}
+	return start, end, pageInfo, nil
 }
@@ -6,13 +6,77 @@ package graph
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/konradreiche/re/internal/commandtest/fakegithub/graph/model"
 )
 
+// CheckSuites is the resolver for the checkSuites field.
+func (r *commitResolver) CheckSuites(ctx context.Context, obj *model.Commit, after *string, before *string, first *int32, last *int32) (*model.CheckSuiteConnection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.Resolver.CheckSuites) == 0 {
+		return obj.CheckSuites, nil
+	}
+	suites := r.Resolver.CheckSuites[0]
+	if len(r.Resolver.CheckSuites) > 1 {
+		r.Resolver.CheckSuites = r.Resolver.CheckSuites[1:]
+	}
+	return suites, nil
+}
+
+// DismissPullRequestReview is the resolver for the dismissPullRequestReview field.
+func (r *mutationResolver) DismissPullRequestReview(ctx context.Context, input model.DismissPullRequestReviewInput) (*model.DismissPullRequestReviewPayload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, pr := range r.Repo.PullRequests.Edges {
+		for _, edge := range pr.Node.Reviews.Edges {
+			review := edge.Node
+			if review.ID != input.PullRequestReviewID {
+				continue
+			}
+			// Like GitHub, only reviews that approve or request changes can
+			// be dismissed.
+			if review.State != model.PullRequestReviewStateApproved && review.State != model.PullRequestReviewStateChangesRequested {
+				return nil, fmt.Errorf("review %s cannot be dismissed", review.ID)
+			}
+			review.State = model.PullRequestReviewStateDismissed
+			return &model.DismissPullRequestReviewPayload{
+				ClientMutationID:  input.ClientMutationID,
+				PullRequestReview: review,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("could not resolve to a node with the global id of '%s'", input.PullRequestReviewID)
+}
+
+// Reviews is the resolver for the reviews field.
+func (r *pullRequestResolver) Reviews(ctx context.Context, obj *model.PullRequest, after *string, author *string, before *string, first *int32, last *int32, states []model.PullRequestReviewState) (*model.PullRequestReviewConnection, error) {
+	edges := obj.Reviews.Edges
+	start, end, pageInfo, err := paginate(len(edges), after, first)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PullRequestReviewConnection{
+		Edges:      edges[start:end],
+		PageInfo:   pageInfo,
+		TotalCount: int32(len(edges)),
+	}, nil
+}
+
+// RateLimit is the resolver for the rateLimit field.
+func (r *queryResolver) RateLimit(ctx context.Context, dryRun *bool) (*model.RateLimit, error) {
+	return &model.RateLimit{
+		Cost:      1,
+		Limit:     5000,
+		Remaining: 4999,
+		ResetAt:   "2006-01-02T15:04:05Z",
+	}, nil
+}
+
 // Repository is the resolver for the repository field.
 func (r *queryResolver) Repository(ctx context.Context, followRenames *bool, name string, owner string) (*model.Repository, error) {
-	return r.Resolver.Repository, nil
+	return r.Resolver.Repo, nil
 }
 
 // Search is the resolver for the search field.
@@ -25,7 +89,47 @@ func (r *queryResolver) Viewer(ctx context.Context) (*model.User, error) {
 	return r.Resolver.Viewer, nil
 }
 
+// PullRequest is the resolver for the pullRequest field.
+func (r *repositoryResolver) PullRequest(ctx context.Context, obj *model.Repository, number int32) (*model.PullRequest, error) {
+	for _, edge := range obj.PullRequests.Edges {
+		if edge.Node.Number == number {
+			return edge.Node, nil
+		}
+	}
+	return nil, fmt.Errorf("Could not resolve to a PullRequest with the number of %d.", number)
+}
+
+// PullRequests is the resolver for the pullRequests field.
+func (r *repositoryResolver) PullRequests(ctx context.Context, obj *model.Repository, after *string, baseRefName *string, before *string, first *int32, headRefName *string, labels []string, last *int32, orderBy *model.IssueOrder, states []model.PullRequestState) (*model.PullRequestConnection, error) {
+	edges := obj.PullRequests.Edges
+	start, end, pageInfo, err := paginate(len(edges), after, first)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PullRequestConnection{
+		Edges:      edges[start:end],
+		PageInfo:   pageInfo,
+		TotalCount: int32(len(edges)),
+	}, nil
+}
+
+// Commit returns CommitResolver implementation.
+func (r *Resolver) Commit() CommitResolver { return &commitResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// PullRequest returns PullRequestResolver implementation.
+func (r *Resolver) PullRequest() PullRequestResolver { return &pullRequestResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Repository returns RepositoryResolver implementation.
+func (r *Resolver) Repository() RepositoryResolver { return &repositoryResolver{r} }
+
+type commitResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type pullRequestResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type repositoryResolver struct{ *Resolver }
@@ -1,25 +1,261 @@
 package re
 
-import "os"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const defaultHost = "github.com"
 
 type Config struct {
+	// Host is the host name of the GitHub instance, such as github.com.
+	Host         string
 	RESTEndpoint string
 	Endpoint     string
 	AccessToken  string
+	// TokenSource describes where the access token was found.
+	TokenSource string
+	// OAuthClientID is the client ID of the OAuth app used to log in with the
+	// device flow.
+	OAuthClientID string
+
+	// Lines is the default of the --lines flag.
+	Lines int
+	// Browser is the command used to open pull requests.
+	Browser string
+	// Pager is the command that long output such as a conversation is piped
+	// through. Output is not paged if it is empty.
+	Pager string
+	// DiffTool is the command that diffs are piped through.
+	DiffTool string
+	// BaseRemote is the git remote of the repository pull requests are
+	// opened against, HeadRemote the one branches are pushed to. They are
+	// detected if empty, see [DetectRemotes].
+	BaseRemote string
+	HeadRemote string
+	// Repos are the repositories synced for offline use, as owner/name.
+	Repos []string
+	// CacheDir is where API responses are cached. Caching is disabled if it
+	// is empty.
+	CacheDir string
+}
+
+// File is the configuration file, which is read from
+// $XDG_CONFIG_HOME/re/config.toml:
+//
+//	lines = 50
+//	browser = "firefox"
+//	pager = "less -R"
+//	diff_tool = "delta"
+//	repos = ["konradreiche/re"]
+//	base_remote = "upstream"
+//	head_remote = "origin"
+//
+//	[profiles.work]
+//	host = "github.example.com"
+//	token = "..."
+//
+// The profile whose host matches the host of the origin remote is used. A
+// profile without host applies to github.com. The remotes base_remote and
+// head_remote override those detected when working from a fork.
+type File struct {
+	Lines      int                `toml:"lines"`
+	Browser    string             `toml:"browser"`
+	Pager      string             `toml:"pager"`
+	DiffTool   string             `toml:"diff_tool"`
+	Repos      []string           `toml:"repos"`
+	BaseRemote string             `toml:"base_remote"`
+	HeadRemote string             `toml:"head_remote"`
+	Profiles   map[string]Profile `toml:"profiles"`
+}
+
+// Profile holds the settings of a single GitHub instance.
+type Profile struct {
+	Host  string `toml:"host"`
+	Token string `toml:"token"`
+	// OAuthClientID enables logging in with the OAuth device flow.
+	OAuthClientID string `toml:"oauth_client_id"`
+	// Endpoint and RESTEndpoint default to the API endpoints of the host.
+	Endpoint     string `toml:"endpoint"`
+	RESTEndpoint string `toml:"rest_endpoint"`
+}
+
+// NewConfig loads the configuration file, selects the profile matching the
+// host of the origin remote and applies the environment, which takes
+// precedence over the file. The access token is looked up in the environment
+// first, then in the credential store and finally in the profile.
+func NewConfig() (Config, error) {
+	file, err := LoadConfigFile()
+	if err != nil {
+		return Config{}, err
+	}
+	store, err := NewCredentialStore()
+	if err != nil {
+		return Config{}, err
+	}
+	// Outside of a repository there is no remote, github.com is used then.
+	host, _ := OriginHost(context.Background(), NewExecGit(""))
+	config, err := newConfig(file, host, os.Getenv, store)
+	if err != nil {
+		return Config{}, err
+	}
+	config.CacheDir, err = cacheDir()
+	if err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+func newConfig(file File, host string, getenv func(string) string, store CredentialStore) (Config, error) {
+	config := Config{
+		Host:     defaultHost,
+		Lines:    file.Lines,
+		Browser:  file.Browser,
+		Pager:    file.Pager,
+		DiffTool: file.DiffTool,
+		Repos:    file.Repos,
+
+		BaseRemote: file.BaseRemote,
+		HeadRemote: file.HeadRemote,
+	}
+	profile, ok := file.profile(host)
+	if !ok {
+		profile, _ = file.profile(defaultHost)
+	}
+	if profile.Host != "" {
+		config.Host = profile.Host
+	}
+	config.Endpoint, config.RESTEndpoint = apiEndpoints("https://" + config.Host)
+	if profile.Endpoint != "" {
+		config.Endpoint = profile.Endpoint
+	}
+	if profile.RESTEndpoint != "" {
+		config.RESTEndpoint = profile.RESTEndpoint
+	}
+	config.OAuthClientID = profile.OAuthClientID
+
+	if ghe := getenv("GITHUB_ENTERPRISE_URL"); ghe != "" {
+		config.Host = strings.TrimPrefix(strings.TrimPrefix(ghe, "https://"), "http://")
+		config.Endpoint, config.RESTEndpoint = apiEndpoints(ghe)
+	}
+	switch {
+	case config.Host == defaultHost && getenv("GH_TOKEN") != "":
+		config.AccessToken, config.TokenSource = getenv("GH_TOKEN"), "GH_TOKEN"
+	case config.Host != defaultHost && getenv("GH_ENTERPRISE_TOKEN") != "":
+		config.AccessToken, config.TokenSource = getenv("GH_ENTERPRISE_TOKEN"), "GH_ENTERPRISE_TOKEN"
+	default:
+		token, err := store.Get(config.Host)
+		if err == nil {
+			config.AccessToken, config.TokenSource = token, store.Name()
+		} else if !errors.Is(err, ErrNoCredentials) {
+			return Config{}, err
+		} else if profile.Token != "" {
+			config.AccessToken, config.TokenSource = profile.Token, "config file"
+		}
+	}
+	if browser := getenv("BROWSER"); browser != "" {
+		config.Browser = browser
+	}
+	if pager := getenv("RE_PAGER"); pager != "" {
+		config.Pager = pager
+	}
+	if diffTool := getenv("RE_DIFF_TOOL"); diffTool != "" {
+		config.DiffTool = diffTool
+	}
+	if config.Browser == "" {
+		config.Browser = "chromium"
+	}
+	if config.DiffTool == "" {
+		config.DiffTool = "delta"
+	}
+	return config, nil
+}
+
+// WebURL returns the URL of the web interface of the GitHub instance.
+func (c Config) WebURL() string {
+	host := c.Host
+	if host == "" {
+		host = defaultHost
+	}
+	return "https://" + host
+}
+
+func apiEndpoints(url string) (string, string) {
+	if url == "https://"+defaultHost {
+		return "https://api.github.com", "https://api.github.com"
+	}
+	return url + "/api", url + "/api/v3"
+}
+
+// profile returns the profile for a host. Profiles are checked in the order
+// of their names, [File.validate] makes sure that at most one matches.
+func (f File) profile(host string) (Profile, bool) {
+	for _, name := range slices.Sorted(maps.Keys(f.Profiles)) {
+		profile := f.Profiles[name]
+		if strings.EqualFold(profile.host(), host) {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// validate returns an error if more than one profile applies to the same host,
+// since it would be ambiguous which token to use.
+func (f File) validate() error {
+	names := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(f.Profiles)) {
+		host := strings.ToLower(f.Profiles[name].host())
+		if other, ok := names[host]; ok {
+			return fmt.Errorf("profiles %s and %s are both for %s", other, name, host)
+		}
+		names[host] = name
+	}
+	return nil
+}
+
+// host returns the host the profile applies to.
+func (p Profile) host() string {
+	if p.Host == "" {
+		return defaultHost
+	}
+	return p.Host
+}
+
+// LoadConfigFile reads the configuration file. A missing file is not an error.
+func LoadConfigFile() (File, error) {
+	path, err := configPath()
+	if err != nil {
+		return File{}, err
+	}
+	var file File
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return File{}, nil
+		}
+		return File{}, fmt.Errorf("LoadConfigFile: %w", err)
+	}
+	if err := file.validate(); err != nil {
+		return File{}, fmt.Errorf("LoadConfigFile: %s: %w", path, err)
+	}
+	return file, nil
 }
 
-func NewConfig() Config {
-	endpoint := "https://api.github.com"
-	restEndpoint := "https://api.github.com"
-	accessToken := os.Getenv("GH_TOKEN")
-	if ghe := os.Getenv("GITHUB_ENTERPRISE_URL"); ghe != "" {
-		restEndpoint = ghe + "/api/v3"
-		endpoint = ghe + "/api"
-		accessToken = os.Getenv("GH_ENTERPRISE_TOKEN")
+func configPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "re", "config.toml"), nil
 	}
-	return Config{
-		RESTEndpoint: restEndpoint,
-		Endpoint:     endpoint,
-		AccessToken:  accessToken,
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(home, ".config", "re", "config.toml"), nil
 }
@@ -0,0 +1,117 @@
+package re
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewConfig(t *testing.T) {
+	file := File{
+		Lines: 50,
+		Pager: "less -R",
+		Profiles: map[string]Profile{
+			"personal": {Token: "personal-token"},
+			"work":     {Host: "github.example.com", Token: "work-token"},
+		},
+	}
+	tests := []struct {
+		name string
+		host string
+		env  map[string]string
+		want Config
+	}{
+		{
+			name: "github",
+			host: "github.com",
+			want: Config{
+				Host:         "github.com",
+				Endpoint:     "https://api.github.com",
+				RESTEndpoint: "https://api.github.com",
+				AccessToken:  "personal-token",
+				TokenSource:  "config file",
+				Lines:        50,
+				Browser:      "chromium",
+				Pager:        "less -R",
+				DiffTool:     "delta",
+			},
+		},
+		{
+			name: "enterprise",
+			host: "github.example.com",
+			env:  map[string]string{"GH_TOKEN": "ignored", "RE_PAGER": "more"},
+			want: Config{
+				Host:         "github.example.com",
+				Endpoint:     "https://github.example.com/api",
+				RESTEndpoint: "https://github.example.com/api/v3",
+				AccessToken:  "stored-token",
+				TokenSource:  "memory",
+				Lines:        50,
+				Browser:      "chromium",
+				Pager:        "more",
+				DiffTool:     "delta",
+			},
+		},
+		{
+			name: "environment",
+			host: "github.com",
+			env: map[string]string{
+				"GITHUB_ENTERPRISE_URL": "https://ghe.example.org",
+				"GH_ENTERPRISE_TOKEN":   "env-token",
+				"BROWSER":               "firefox",
+			},
+			want: Config{
+				Host:         "ghe.example.org",
+				Endpoint:     "https://ghe.example.org/api",
+				RESTEndpoint: "https://ghe.example.org/api/v3",
+				AccessToken:  "env-token",
+				TokenSource:  "GH_ENTERPRISE_TOKEN",
+				Lines:        50,
+				Browser:      "firefox",
+				Pager:        "less -R",
+				DiffTool:     "delta",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string {
+				return tt.env[key]
+			}
+			store := memoryStore{"github.example.com": "stored-token"}
+			got, err := newConfig(file, tt.host, getenv, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileDuplicateHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	config := `
+[profiles.personal]
+token = "personal-token"
+
+[profiles.other]
+host = "GitHub.com"
+token = "other-token"
+`
+	if err := os.MkdirAll(filepath.Join(dir, "re"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "re", "config.toml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfigFile()
+	if err == nil || !strings.Contains(err.Error(), "profiles other and personal are both for github.com") {
+		t.Errorf("got %v, want an error for profiles of the same host", err)
+	}
+}
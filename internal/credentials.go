@@ -0,0 +1,317 @@
+package re
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrNoCredentials is returned by a [CredentialStore] if no token is stored
+// for a host.
+var ErrNoCredentials = errors.New("no credentials stored")
+
+// CredentialStore persists access tokens per GitHub host.
+type CredentialStore interface {
+	Get(host string) (string, error)
+	Set(host, token string) error
+	Delete(host string) error
+	// Name describes where tokens are stored.
+	Name() string
+}
+
+// NewCredentialStore returns the keyring of the operating system if it is
+// available and an encrypted file otherwise.
+func NewCredentialStore() (CredentialStore, error) {
+	if store, ok := newKeyringStore(); ok {
+		return store, nil
+	}
+	return newFileStore()
+}
+
+const keyringService = "re"
+
+// keyringStore stores tokens in the keyring of the operating system through
+// its command-line interface: the login keychain on macOS and the Secret
+// Service, such as GNOME Keyring, on Linux.
+type keyringStore struct {
+	get    func(host string) *exec.Cmd
+	set    func(host, token string) *exec.Cmd
+	delete func(host string) *exec.Cmd
+	// notFound reports whether get failed because there is no entry, as
+	// opposed to the keyring being locked or unavailable.
+	notFound func(err *exec.ExitError) bool
+}
+
+func newKeyringStore() (*keyringStore, bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err != nil {
+			return nil, false
+		}
+		return macOSKeychain(), true
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, false
+		}
+		// Without a session bus there is no Secret Service to talk to.
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return nil, false
+		}
+		return secretService(), true
+	}
+	return nil, false
+}
+
+// macOSKeychain stores tokens in the login keychain with security(1).
+func macOSKeychain() *keyringStore {
+	return &keyringStore{
+		get: func(host string) *exec.Cmd {
+			return exec.Command("security", "find-generic-password", "-s", keyringService, "-a", host, "-w")
+		},
+		set: func(host, token string) *exec.Cmd {
+			// The command is read from stdin in interactive mode, since
+			// arguments are visible to other users of the machine.
+			cmd := exec.Command("security", "-i")
+			cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+				quoteArg(keyringService), quoteArg(host), quoteArg(token)))
+			return cmd
+		},
+		delete: func(host string) *exec.Cmd {
+			return exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", host)
+		},
+		notFound: func(err *exec.ExitError) bool {
+			// errSecItemNotFound
+			return err.ExitCode() == 44
+		},
+	}
+}
+
+// secretService stores tokens in the Secret Service with secret-tool(1).
+func secretService() *keyringStore {
+	return &keyringStore{
+		get: func(host string) *exec.Cmd {
+			return exec.Command("secret-tool", "lookup", "service", keyringService, "host", host)
+		},
+		set: func(host, token string) *exec.Cmd {
+			cmd := exec.Command("secret-tool", "store", "--label", keyringService+": "+host, "service", keyringService, "host", host)
+			cmd.Stdin = strings.NewReader(token)
+			return cmd
+		},
+		delete: func(host string) *exec.Cmd {
+			return exec.Command("secret-tool", "clear", "service", keyringService, "host", host)
+		},
+		notFound: func(err *exec.ExitError) bool {
+			// A missing entry fails silently, other errors are reported.
+			return err.ExitCode() == 1 && len(bytes.TrimSpace(err.Stderr)) == 0
+		},
+	}
+}
+
+// quoteArg quotes an argument for the interactive mode of security(1), which
+// splits lines like a shell.
+func quoteArg(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func (s *keyringStore) Get(host string) (string, error) {
+	cmd := s.get(host)
+	b, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("keyringStore.Get: %w", err)
+		}
+		if s.notFound(exitErr) {
+			return "", ErrNoCredentials
+		}
+		return "", formatCommandError("keyringStore.Get", cmd, exitErr.Stderr)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", ErrNoCredentials
+	}
+	return token, nil
+}
+
+func (s *keyringStore) Set(host, token string) error {
+	cmd := s.set(host, token)
+	if b, err := cmd.CombinedOutput(); err != nil {
+		return formatCommandError("keyringStore.Set", cmd, b)
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(host string) error {
+	if _, err := s.Get(host); err != nil {
+		return err
+	}
+	cmd := s.delete(host)
+	if b, err := cmd.CombinedOutput(); err != nil {
+		return formatCommandError("keyringStore.Delete", cmd, b)
+	}
+	return nil
+}
+
+func (s *keyringStore) Name() string {
+	return "keyring"
+}
+
+// fileStore stores tokens in a file encrypted with AES-GCM. The key is kept in
+// the state directory, separate from the configuration directory, so that a
+// synced or published configuration does not disclose the tokens.
+type fileStore struct {
+	path    string
+	keyPath string
+}
+
+func newFileStore() (*fileStore, error) {
+	config, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	state, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{
+		path:    filepath.Join(filepath.Dir(config), "credentials"),
+		keyPath: filepath.Join(state, "credentials.key"),
+	}, nil
+}
+
+func (s *fileStore) Get(host string) (string, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[host]
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	return token, nil
+}
+
+func (s *fileStore) Set(host, token string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[host] = token
+	return s.save(tokens)
+}
+
+func (s *fileStore) Delete(host string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[host]; !ok {
+		return ErrNoCredentials
+	}
+	delete(tokens, host)
+	return s.save(tokens)
+}
+
+func (s *fileStore) Name() string {
+	return "encrypted file " + s.path
+}
+
+func (s *fileStore) load() (map[string]string, error) {
+	tokens := make(map[string]string)
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	aead, err := s.cipher(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < aead.NonceSize() {
+		return nil, fmt.Errorf("fileStore: %s is corrupt", s.path)
+	}
+	nonce, ciphertext := b[:aead.NonceSize()], b[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fileStore: cannot decrypt %s: %w", s.path, err)
+	}
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, fmt.Errorf("fileStore: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *fileStore) save(tokens map[string]string) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	aead, err := s.cipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, aead.Seal(nonce, nonce, plaintext, nil))
+}
+
+// cipher returns the cipher for the credentials file. If create is set, a new
+// key is generated if there is none yet.
+func (s *fileStore) cipher(create bool) (cipher.AEAD, error) {
+	key, err := os.ReadFile(s.keyPath)
+	if errors.Is(err, fs.ErrNotExist) && create {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(s.keyPath, key); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("fileStore: cannot read key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("fileStore: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes a file readable only by the current user, replacing
+// any previous version at once so that it is never left half-written.
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
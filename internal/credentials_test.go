@@ -0,0 +1,133 @@
+package re
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// memoryStore is a [CredentialStore] for tests.
+type memoryStore map[string]string
+
+func (s memoryStore) Get(host string) (string, error) {
+	token, ok := s[host]
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	return token, nil
+}
+
+func (s memoryStore) Set(host, token string) error {
+	s[host] = token
+	return nil
+}
+
+func (s memoryStore) Delete(host string) error {
+	delete(s, host)
+	return nil
+}
+
+func (s memoryStore) Name() string {
+	return "memory"
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store := &fileStore{
+		path:    filepath.Join(dir, "config", "credentials"),
+		keyPath: filepath.Join(dir, "state", "credentials.key"),
+	}
+	if _, err := store.Get("github.com"); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("got %v, want %v", err, ErrNoCredentials)
+	}
+	if err := store.Set("github.com", "secret-token"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) == 0 || string(b) == "secret-token" {
+		t.Fatalf("credentials not encrypted: %q", b)
+	}
+	token, err := store.Get("github.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "secret-token" {
+		t.Errorf("got %q, want %q", token, "secret-token")
+	}
+	if err := store.Delete("github.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("github.com"); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("got %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestKeyringStoreGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "found",
+			script: "echo secret-token",
+			want:   "secret-token",
+		},
+		{
+			name:    "not-found",
+			script:  "exit 1",
+			wantErr: ErrNoCredentials,
+		},
+		{
+			name:   "locked",
+			script: "echo 'Cannot unlock the collection' >&2; exit 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := secretService()
+			store.get = func(host string) *exec.Cmd {
+				return exec.Command("sh", "-c", tt.script)
+			}
+			token, err := store.Get("github.com")
+			if tt.want == "" && err == nil {
+				t.Fatalf("got token %q, want error", token)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && tt.want == "" && errors.Is(err, ErrNoCredentials) {
+				t.Fatalf("got %v, want a keyring error", err)
+			}
+			if token != tt.want {
+				t.Errorf("got %q, want %q", token, tt.want)
+			}
+		})
+	}
+}
+
+func TestMacOSKeychainSet(t *testing.T) {
+	cmd := macOSKeychain().set("github.com", "it's-secret")
+	if slices.ContainsFunc(cmd.Args, func(arg string) bool { return strings.Contains(arg, "it's-secret") }) {
+		t.Fatalf("token in arguments: %q", cmd.Args)
+	}
+	b, err := io.ReadAll(cmd.Stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `add-generic-password -U -s 're' -a 'github.com' -w 'it'"'"'s-secret'` + "\n"
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
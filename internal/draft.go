@@ -0,0 +1,122 @@
+package re
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Draft is a pending review of a pull request. Comments are collected locally
+// over several invocations and submitted together as a single review. Drafts
+// are persisted to disk so that they survive across processes and reboots.
+type Draft struct {
+	Owner    string          `json:"owner"`
+	Name     string          `json:"name"`
+	Number   int             `json:"number"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// LoadDraft reads the draft review of the given pull request from disk. An
+// empty draft is returned if none has been started yet.
+func LoadDraft(owner, name string, number int) (*Draft, error) {
+	draft := &Draft{
+		Owner:  owner,
+		Name:   name,
+		Number: number,
+	}
+	path, err := draft.path()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return draft, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, draft); err != nil {
+		return nil, fmt.Errorf("LoadDraft: %s: %w", path, err)
+	}
+	return draft, nil
+}
+
+// Save writes the draft to disk. The file is replaced atomically so that a
+// crash while writing never leaves a truncated draft behind.
+func (d *Draft) Save() error {
+	path, err := d.path()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b)
+}
+
+// Delete removes the draft from disk.
+func (d *Draft) Delete() error {
+	path, err := d.path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Comment returns the comment at the given one-based index as it is displayed
+// when listing the draft.
+func (d *Draft) Comment(index int) (*ReviewComment, error) {
+	if index < 1 || index > len(d.Comments) {
+		return nil, fmt.Errorf("draft of #%d has no comment %d", d.Number, index)
+	}
+	return &d.Comments[index-1], nil
+}
+
+// RemoveComment removes the comment at the given one-based index.
+func (d *Draft) RemoveComment(index int) error {
+	if _, err := d.Comment(index); err != nil {
+		return err
+	}
+	d.Comments = append(d.Comments[:index-1], d.Comments[index:]...)
+	return nil
+}
+
+func (d *Draft) path() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "drafts", d.Owner, d.Name, fmt.Sprintf("%d.json", d.Number)), nil
+}
+
+// stateDir returns the directory for data that should persist between
+// invocations, following the XDG Base Directory Specification.
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "re"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "re"), nil
+}
+
+var reviewEvents = []string{"APPROVE", "COMMENT", "REQUEST_CHANGES"}
+
+func parseReviewEvent(event string) (string, error) {
+	event = strings.ToUpper(event)
+	for _, e := range reviewEvents {
+		if e == event {
+			return event, nil
+		}
+	}
+	return "", fmt.Errorf("invalid review event %q: must be one of %s", event, strings.Join(reviewEvents, ", "))
+}
@@ -0,0 +1,49 @@
+package re
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDraft(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	draft, err := LoadDraft("konradreiche", "re", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(draft.Comments) != 0 {
+		t.Fatalf("got %d comments, want none", len(draft.Comments))
+	}
+	draft.Comments = []ReviewComment{
+		{Path: "main.go", Body: "a", Line: 1, Side: SideRight},
+		{Path: "main.go", Body: "b", Line: 2, Side: SideRight},
+		{Path: "main.go", Body: "c", Line: 3, Side: SideRight},
+	}
+	if err := draft.RemoveComment(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := draft.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadDraft("konradreiche", "re", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, draft); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	if err := got.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadDraft("konradreiche", "re", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Comments) != 0 {
+		t.Fatalf("got %d comments after delete, want none", len(got.Comments))
+	}
+}
@@ -0,0 +1,166 @@
+package re
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Format selects how listing commands write their results. The zero value
+// renders colored, human-readable text.
+type Format struct {
+	kind     string
+	template *template.Template
+}
+
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatNDJSON   = "ndjson"
+	formatTSV      = "tsv"
+	formatTemplate = "template"
+)
+
+// ParseFormat parses the value of the --format flag: text, json, ndjson, tsv
+// or template=<go-template>. A template is executed once for every item.
+func ParseFormat(s string) (Format, error) {
+	kind, text, _ := strings.Cut(s, "=")
+	switch kind {
+	case "", formatText:
+		return Format{kind: formatText}, nil
+	case formatJSON, formatNDJSON, formatTSV:
+		return Format{kind: kind}, nil
+	case formatTemplate:
+		if text == "" {
+			return Format{}, fmt.Errorf("invalid format %q: template is empty", s)
+		}
+		tmpl, err := template.New("format").Parse(text)
+		if err != nil {
+			return Format{}, fmt.Errorf("invalid format %q: %w", s, err)
+		}
+		return Format{kind: kind, template: tmpl}, nil
+	}
+	return Format{}, fmt.Errorf("invalid format %q: must be one of text, json, ndjson, tsv or template=<go-template>", s)
+}
+
+// IsText reports whether results should be rendered for humans.
+func (f Format) IsText() bool {
+	return f.kind == "" || f.kind == formatText
+}
+
+// writeFormatted writes items in a machine-readable format. The row function
+// returns the columns of an item for tab-separated output.
+func writeFormatted[T any](w io.Writer, f Format, items []T, row func(T) []string) error {
+	switch f.kind {
+	case formatJSON:
+		if items == nil {
+			items = []T{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case formatNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, item := range items {
+			if err := encoder.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTSV:
+		for _, item := range items {
+			columns := row(item)
+			for i, column := range columns {
+				columns[i] = escapeTSV(column)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(columns, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTemplate:
+		for _, item := range items {
+			if err := f.template.Execute(w, item); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("writeFormatted: unsupported format %q", f.kind)
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func escapeTSV(s string) string {
+	return tsvEscaper.Replace(s)
+}
+
+func pullRequestRow(pr PullRequestSummary) []string {
+	return []string{
+		fmt.Sprint(pr.Number),
+		pr.Repository,
+		pr.Author,
+		pr.Title,
+		fmt.Sprint(pr.Comments),
+		string(pr.Status),
+		pr.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func commentRow(comment *ConversationComment) []string {
+	line := ""
+	if comment.Line > 0 {
+		line = fmt.Sprint(comment.Line)
+	}
+	return []string{
+		fmt.Sprint(comment.ID),
+		comment.CreatedAt.Format(time.RFC3339),
+		comment.Author,
+		comment.Path,
+		line,
+		comment.Body,
+	}
+}
+
+func fileDiffRow(file FileDiff) []string {
+	return []string{
+		file.Status,
+		file.Filename,
+		file.PreviousFilename,
+		fmt.Sprint(file.Changes),
+	}
+}
+
+func inboxRow(item InboxItem) []string {
+	return []string{
+		item.UpdatedAt.Format(time.RFC3339),
+		item.Reason,
+		item.Owner + "/" + item.Repository,
+		fmt.Sprint(item.Number),
+		item.Title,
+	}
+}
+
+func reviewCommentRow(comment ReviewComment) []string {
+	return []string{
+		comment.Location(),
+		comment.Side,
+		comment.Body,
+	}
+}
+
+func checkRow(check Check) []string {
+	return []string{
+		check.Workflow,
+		check.Name,
+		check.State,
+		fmt.Sprint(check.Duration().Seconds()),
+		check.URL,
+	}
+}
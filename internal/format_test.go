@@ -0,0 +1,73 @@
+package re
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteFormatted(t *testing.T) {
+	pullRequests := []PullRequestSummary{
+		{
+			Number:     1,
+			Title:      "Fix\ttabs",
+			Author:     "foo",
+			Repository: "re",
+			CreatedAt:  time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			Comments:   2,
+			Status:     StatusStateSuccess,
+		},
+		{
+			Number:     2,
+			Title:      "Add format",
+			Author:     "bar",
+			Repository: "re",
+			CreatedAt:  time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			Status:     StatusStatePending,
+		},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "tsv",
+			want: "1\tre\tfoo\tFix\\ttabs\t2\tSUCCESS\t2006-01-02T15:04:05Z\n" +
+				"2\tre\tbar\tAdd format\t0\tPENDING\t2006-01-02T15:04:05Z\n",
+		},
+		{
+			format: "template={{.Number}}: {{.Title}}",
+			want:   "1: Fix\ttabs\n2: Add format\n",
+		},
+		{
+			format: "ndjson",
+			want: `{"number":1,"title":"Fix\ttabs","author":"foo","repository":"re","createdAt":"2006-01-02T15:04:05Z","comments":2,"status":"SUCCESS","participating":false,"awaitingViewer":false,"viewerIsAuthor":false}` + "\n" +
+				`{"number":2,"title":"Add format","author":"bar","repository":"re","createdAt":"2006-01-02T15:04:05Z","comments":0,"status":"PENDING","participating":false,"awaitingViewer":false,"viewerIsAuthor":false}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			format, err := ParseFormat(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writeFormatted(&buf, format, pullRequests, pullRequestRow); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(buf.String(), tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseFormatInvalid(t *testing.T) {
+	for _, format := range []string{"xml", "template=", "template={{.Number"} {
+		if _, err := ParseFormat(format); err == nil {
+			t.Errorf("ParseFormat(%q): expected error", format)
+		}
+	}
+}
@@ -3,113 +3,438 @@ package re
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-func GetRepositoryAndOrgName() (string, string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	b, err := cmd.CombinedOutput()
+// Git is the repository that commands operate on. [ExecGit] runs the git
+// binary, tests use an in-memory fake so that commands can be tested without
+// a repository.
+type Git interface {
+	// CurrentBranch returns the checked out branch, which is empty if HEAD
+	// is detached.
+	CurrentBranch(ctx context.Context) (string, error)
+	// CommitMessage returns the subject of a commit followed by a newline and
+	// its body.
+	CommitMessage(ctx context.Context, rev string) (string, error)
+	// RevParse returns the commit a revision points to.
+	RevParse(ctx context.Context, rev string) (string, error)
+	// IsAncestor reports whether the commit ancestor is reachable from rev.
+	IsAncestor(ctx context.Context, ancestor, rev string) (bool, error)
+	// MergeBase returns the best common ancestor of two commits.
+	MergeBase(ctx context.Context, a, b string) (string, error)
+	// CountCommits returns the number of commits reachable from rev but not
+	// from base.
+	CountCommits(ctx context.Context, base, rev string) (int, error)
+
+	// Branches returns the names of the local branches matching the filter.
+	Branches(ctx context.Context, filter BranchFilter) ([]string, error)
+	// Switch checks out a branch. If startPoint is set, the branch is
+	// created there.
+	Switch(ctx context.Context, branch, startPoint string) error
+	// SetBranch points a branch that is not checked out to a commit.
+	SetBranch(ctx context.Context, branch, commit string) error
+	DeleteBranch(ctx context.Context, branch string) error
+	// Merge fast-forwards the current branch to a commit and fails if that is
+	// not possible.
+	Merge(ctx context.Context, commit string) error
+	// Rebase replays the commits of branch that are not reachable from
+	// upstream onto the commit onto, leaving branch checked out.
+	Rebase(ctx context.Context, onto, upstream, branch string) error
+
+	// Config returns the value of a setting, which is empty if it is not
+	// set.
+	Config(ctx context.Context, key string) (string, error)
+	// SetConfig sets a setting in the repository configuration.
+	SetConfig(ctx context.Context, key, value string) error
+
+	// Remotes returns the names of the configured remotes.
+	Remotes(ctx context.Context) ([]string, error)
+	// RemoteURL returns the URL of a remote, rewritten according to the
+	// url.<base>.insteadOf settings.
+	RemoteURL(ctx context.Context, remote string) (string, error)
+	AddRemote(ctx context.Context, name, url string) error
+	// DefaultBranch returns the default branch of a remote.
+	DefaultBranch(ctx context.Context, remote string) (string, error)
+	// Fetch updates the remote-tracking branches of a remote.
+	Fetch(ctx context.Context, remote string) error
+	// FetchRef fetches a single ref from a remote and returns the commit it
+	// points to.
+	FetchRef(ctx context.Context, remote, ref string) (string, error)
+	// Push pushes a branch to a remote and returns the output of git, which
+	// includes the messages of the remote. With forceWithLease, the branch is
+	// overwritten unless it changed on the remote since it was last fetched.
+	Push(ctx context.Context, remote, branch string, forceWithLease bool) (string, error)
+
+	// Worktrees returns the worktrees of the repository, starting with the
+	// main worktree.
+	Worktrees(ctx context.Context) ([]Worktree, error)
+	// AddWorktree checks out a branch into a new worktree. If startPoint is
+	// set, the branch is created there.
+	AddWorktree(ctx context.Context, path, branch, startPoint string) error
+	// RemoveWorktree removes a worktree, which fails if it has changes.
+	RemoveWorktree(ctx context.Context, path string) error
+	// PruneWorktrees drops the administrative data of worktrees whose
+	// directory was deleted.
+	PruneWorktrees(ctx context.Context) error
+}
+
+// BranchFilter selects branches by their relation to other commits. Empty
+// fields do not filter.
+type BranchFilter struct {
+	// Merged selects the branches reachable from this commit.
+	Merged string
+	// NoMerged selects the branches not reachable from this commit.
+	NoMerged string
+	// Contains selects the branches that contain this commit.
+	Contains string
+}
+
+// Worktree is a working tree attached to the repository.
+type Worktree struct {
+	Path string
+	// Branch is empty if the worktree has a detached HEAD.
+	Branch string
+}
+
+// ExecGit implements [Git] by running the git binary.
+type ExecGit struct {
+	// Dir is the directory git runs in, the current directory if empty.
+	Dir string
+}
+
+// NewExecGit returns a [Git] for the repository at dir.
+func NewExecGit(dir string) *ExecGit {
+	return &ExecGit{Dir: dir}
+}
+
+// run runs git with the given arguments and returns its output with
+// surrounding whitespace trimmed.
+func (g *ExecGit) run(ctx context.Context, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
+	cmd.Stderr = &stderr
+	b, err := cmd.Output()
 	if err != nil {
-		if code, ok := err.(*exec.ExitError); ok {
-			// TODO: consider moving to calling side
-			if code.ExitCode() == 128 {
-				return "", "", formatCommandError("GetRepositoryAndOrgName", cmd, b)
-			}
-		}
-		return "", "", formatCommandError("GetRepositoryAndOrgName", cmd, b)
+		return "", &gitError{err: formatCommandError("git", cmd, stderr.Bytes()), exitErr: err}
 	}
-	origin := strings.TrimSuffix(string(b), "\n")
-	split := strings.SplitN(origin, ":", 2)
-	if len(split) == 1 {
-		// TODO: consider moving to calling side
-		return "", "", fmt.Errorf("GetRepositoryAndOrgName: invalid string: %s", origin)
+	return strings.TrimSpace(string(b)), nil
+}
+
+// gitError keeps the exit status of git alongside the formatted error, since
+// some commands report a negative answer through the exit status.
+type gitError struct {
+	err     error
+	exitErr error
+}
+
+func (e *gitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *gitError) Unwrap() error {
+	return e.exitErr
+}
+
+// exitCode returns the exit status of git, or -1 if git did not exit.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
 	}
-	return filepath.Dir(split[1]), strings.TrimSuffix(filepath.Base(split[1]), ".git"), nil
+	return -1
 }
 
-func PushToOrigin() error {
-	branch, err := CurrentBranch()
+func (g *ExecGit) CurrentBranch(ctx context.Context) (string, error) {
+	return g.run(ctx, "branch", "--show-current")
+}
+
+func (g *ExecGit) CommitMessage(ctx context.Context, rev string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "log", "-1", "--pretty=format:%s%n%b", rev)
+	cmd.Dir = g.Dir
+	cmd.Stderr = &stderr
+	// The output is not trimmed, the newline after the subject separates it
+	// from an empty body.
+	b, err := cmd.Output()
 	if err != nil {
-		return err
+		return "", formatCommandError("CommitMessage", cmd, stderr.Bytes())
 	}
-	cmd := exec.Command("git", "push", "origin", branch)
-	b, err := cmd.CombinedOutput()
+	return string(b), nil
+}
+
+func (g *ExecGit) RevParse(ctx context.Context, rev string) (string, error) {
+	return g.run(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+func (g *ExecGit) IsAncestor(ctx context.Context, ancestor, rev string) (bool, error) {
+	_, err := g.run(ctx, "merge-base", "--is-ancestor", ancestor, rev)
+	if exitCode(err) == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (g *ExecGit) MergeBase(ctx context.Context, a, b string) (string, error) {
+	return g.run(ctx, "merge-base", a, b)
+}
+
+func (g *ExecGit) CountCommits(ctx context.Context, base, rev string) (int, error) {
+	output, err := g.run(ctx, "rev-list", "--count", base+".."+rev)
 	if err != nil {
-		return fmt.Errorf("PushToOrigin: %s: %s", cmd.String(), string(b))
+		return 0, err
 	}
-	fmt.Println(string(b))
-	return nil
+	return strconv.Atoi(output)
 }
 
-func UpdateOrigin() error {
-	defaultBranch, err := GetDefaultBranch()
+func (g *ExecGit) Branches(ctx context.Context, filter BranchFilter) ([]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname:short)"}
+	if filter.Merged != "" {
+		args = append(args, "--merged", filter.Merged)
+	}
+	if filter.NoMerged != "" {
+		args = append(args, "--no-merged", filter.NoMerged)
+	}
+	if filter.Contains != "" {
+		args = append(args, "--contains", filter.Contains)
+	}
+	output, err := g.run(ctx, append(args, "refs/heads")...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+func (g *ExecGit) Switch(ctx context.Context, branch, startPoint string) error {
+	args := []string{"switch", "--quiet", branch}
+	if startPoint != "" {
+		args = []string{"switch", "--quiet", "--create", branch, startPoint}
+	}
+	_, err := g.run(ctx, args...)
+	return err
+}
+
+func (g *ExecGit) SetBranch(ctx context.Context, branch, commit string) error {
+	_, err := g.run(ctx, "branch", "--force", branch, commit)
+	return err
+}
+
+func (g *ExecGit) DeleteBranch(ctx context.Context, branch string) error {
+	_, err := g.run(ctx, "branch", "--delete", "--force", branch)
+	return err
+}
+
+func (g *ExecGit) Merge(ctx context.Context, commit string) error {
+	_, err := g.run(ctx, "merge", "--quiet", "--ff-only", commit)
+	return err
+}
+
+func (g *ExecGit) Rebase(ctx context.Context, onto, upstream, branch string) error {
+	_, err := g.run(ctx, "rebase", "--quiet", "--onto", onto, upstream, branch)
+	return err
+}
+
+func (g *ExecGit) Config(ctx context.Context, key string) (string, error) {
+	value, err := g.run(ctx, "config", "--get", key)
+	// git config exits with 1 if the setting is not set.
+	if exitCode(err) == 1 {
+		return "", nil
 	}
-	branch, err := CurrentBranch()
+	return value, err
+}
+
+func (g *ExecGit) SetConfig(ctx context.Context, key, value string) error {
+	_, err := g.run(ctx, "config", key, value)
+	return err
+}
+
+func (g *ExecGit) Remotes(ctx context.Context) ([]string, error) {
+	output, err := g.run(ctx, "remote")
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+func (g *ExecGit) RemoteURL(ctx context.Context, remote string) (string, error) {
+	url, err := g.Config(ctx, "remote."+remote+".url")
+	if err != nil {
+		return "", err
 	}
-	if branch == defaultBranch {
-		return fmt.Errorf("UpdateOrigin: not supported for default branch %q", defaultBranch)
+	if url == "" {
+		return "", fmt.Errorf("RemoteURL: no remote named %q", remote)
 	}
-	cmd := exec.Command("git", "push", "origin", branch, "--force-with-lease")
-	b, err := cmd.CombinedOutput()
+	rewrites, err := g.urlRewrites(ctx)
 	if err != nil {
-		return fmt.Errorf("UpdateOrigin: %s: %s", cmd.String(), string(b))
+		return "", err
 	}
-	fmt.Println(string(b))
-	return nil
+	return rewriteURL(url, rewrites), nil
 }
 
-func CheckoutPullRequest(pr int) error {
-	if _, err := exec.Command("git", "fetch", "origin", "pull/"+fmt.Sprint(pr)+"/head").CombinedOutput(); err != nil {
-		return err
+func (g *ExecGit) urlRewrites(ctx context.Context) ([]urlRewrite, error) {
+	output, err := g.run(ctx, "config", "--get-regexp", `^url\..*\.insteadof$`)
+	// git config exits with 1 if there is no matching setting.
+	if exitCode(err) == 1 {
+		return nil, nil
 	}
-	if _, err := exec.Command("git", "checkout", "FETCH_HEAD").CombinedOutput(); err != nil {
-		return err
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return parseURLRewrites(output), nil
 }
 
-func CurrentBranch() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
-	b, err := cmd.CombinedOutput()
+func (g *ExecGit) AddRemote(ctx context.Context, name, url string) error {
+	_, err := g.run(ctx, "remote", "add", name, url)
+	return err
+}
+
+// DefaultBranch reads the default branch from refs/remotes/<remote>/HEAD,
+// which git only sets for the remote a repository was cloned from, so other
+// remotes such as upstream are queried instead.
+func (g *ExecGit) DefaultBranch(ctx context.Context, remote string) (string, error) {
+	ref, err := g.run(ctx, "symbolic-ref", "refs/remotes/"+remote+"/HEAD")
+	if err == nil {
+		return strings.TrimPrefix(ref, "refs/remotes/"+remote+"/"), nil
+	}
+	output, err := g.run(ctx, "ls-remote", "--symref", remote, "HEAD")
 	if err != nil {
+		return "", fmt.Errorf("DefaultBranch: %w", err)
+	}
+	// The first line is "ref: refs/heads/<branch>\tHEAD".
+	line, _, _ := strings.Cut(output, "\n")
+	ref, _, _ = strings.Cut(strings.TrimPrefix(line, "ref: "), "\t")
+	branch, ok := strings.CutPrefix(ref, "refs/heads/")
+	if !ok {
+		return "", fmt.Errorf("DefaultBranch: no default branch for %s", remote)
+	}
+	return branch, nil
+}
+
+func (g *ExecGit) Fetch(ctx context.Context, remote string) error {
+	_, err := g.run(ctx, "fetch", "--quiet", remote)
+	return err
+}
+
+func (g *ExecGit) FetchRef(ctx context.Context, remote, ref string) (string, error) {
+	if _, err := g.run(ctx, "fetch", "--quiet", remote, ref); err != nil {
 		return "", err
 	}
-	return strings.TrimSuffix(string(b), "\n"), nil
+	return g.run(ctx, "rev-parse", "FETCH_HEAD")
 }
 
-func GetTitleAndBody() (string, string, error) {
-	cmd := exec.Command("git", "log", "-1", "--pretty=format:%s%n%b")
+func (g *ExecGit) Push(ctx context.Context, remote, branch string, forceWithLease bool) (string, error) {
+	args := []string{"push", remote, branch}
+	if forceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
 	b, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", "", err
+		return "", formatCommandError("Push", cmd, b)
 	}
-	body, title, err := formatTitleAndBody(b)
+	return string(b), nil
+}
+
+func (g *ExecGit) Worktrees(ctx context.Context) ([]Worktree, error) {
+	output, err := g.run(ctx, "worktree", "list", "--porcelain")
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
+	return parseWorktrees(output), nil
+}
 
-	return body, title, nil
+func (g *ExecGit) AddWorktree(ctx context.Context, path, branch, startPoint string) error {
+	args := []string{"worktree", "add", "--quiet", path, branch}
+	if startPoint != "" {
+		args = []string{"worktree", "add", "--quiet", "-b", branch, path, startPoint}
+	}
+	_, err := g.run(ctx, args...)
+	return err
 }
 
-func GetDefaultBranch() (string, error) {
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
-	b, err := cmd.CombinedOutput()
+func (g *ExecGit) RemoveWorktree(ctx context.Context, path string) error {
+	_, err := g.run(ctx, "worktree", "remove", path)
+	return err
+}
+
+func (g *ExecGit) PruneWorktrees(ctx context.Context) error {
+	_, err := g.run(ctx, "worktree", "prune")
+	return err
+}
+
+// parseWorktrees parses the output of git worktree list --porcelain, which
+// describes each worktree in a block of lines such as "worktree <path>" and
+// "branch refs/heads/<branch>".
+func parseWorktrees(output string) []Worktree {
+	var worktrees []Worktree
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "worktree":
+			worktrees = append(worktrees, Worktree{Path: value})
+		case "branch":
+			if len(worktrees) > 0 {
+				worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(value, "refs/heads/")
+			}
+		}
+	}
+	return worktrees
+}
+
+// OriginHost returns the host name of the origin remote.
+func OriginHost(ctx context.Context, git Git) (string, error) {
+	remote, err := LookupRemote(ctx, git, "origin")
 	if err != nil {
 		return "", err
 	}
-	split := strings.Split(strings.TrimSuffix(string(b), "\n"), "/")
-	return split[len(split)-1], nil
+	return remote.Host, nil
+}
+
+// BranchExists reports whether a local branch exists.
+func BranchExists(ctx context.Context, git Git, branch string) bool {
+	_, err := git.RevParse(ctx, "refs/heads/"+branch)
+	return err == nil
+}
+
+// BranchUpstream returns the remote and the ref a local branch tracks, which
+// are empty if it does not track any.
+func BranchUpstream(ctx context.Context, git Git, branch string) (remote, ref string) {
+	remote, _ = git.Config(ctx, "branch."+branch+".remote")
+	ref, _ = git.Config(ctx, "branch."+branch+".merge")
+	return remote, ref
+}
+
+// SetBranchUpstream makes a local branch track the ref of a remote. Unlike
+// git branch --set-upstream-to, the ref does not need to be fetched into a
+// remote-tracking branch first, which allows tracking refs/pull/<n>/head.
+func SetBranchUpstream(ctx context.Context, git Git, branch, remote, ref string) error {
+	if err := git.SetConfig(ctx, "branch."+branch+".remote", remote); err != nil {
+		return err
+	}
+	return git.SetConfig(ctx, "branch."+branch+".merge", ref)
+}
+
+// CommitTitleAndBody returns the subject and the body of a commit, with the
+// lines of each paragraph of the body joined.
+func CommitTitleAndBody(ctx context.Context, git Git, rev string) (string, string, error) {
+	message, err := git.CommitMessage(ctx, rev)
+	if err != nil {
+		return "", "", err
+	}
+	return formatTitleAndBody([]byte(message))
 }
 
 func formatTitleAndBody(logOutput []byte) (string, string, error) {
 	split := strings.SplitN(string(logOutput), "\n", 2)
+	if len(split) < 2 {
+		return split[0], "", nil
+	}
 	title := split[0]
 	body := split[1]
 
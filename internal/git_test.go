@@ -30,3 +30,26 @@ This is another line to test things.`
 		t.Errorf("diff: %s", diff)
 	}
 }
+
+func TestParseWorktrees(t *testing.T) {
+	output := `worktree /src/re
+HEAD 2b6d1f4c0e2a7b3d9f8e6c5a4b3c2d1e0f9a8b7c
+branch refs/heads/main
+
+worktree /src/re-pr-42
+HEAD 7c8b9a0f1e2d3c4b5a6e7f8d9c0b1a2e3f4d5c6b
+branch refs/heads/fix/typo
+
+worktree /src/re-bisect
+HEAD 0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e
+detached
+`
+	want := []Worktree{
+		{Path: "/src/re", Branch: "main"},
+		{Path: "/src/re-pr-42", Branch: "fix/typo"},
+		{Path: "/src/re-bisect"},
+	}
+	if diff := cmp.Diff(want, parseWorktrees(output)); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
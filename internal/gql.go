@@ -31049,71 +31049,134 @@ type WorkflowsParametersInput struct {
 // A valid x509 certificate string
 type X509Certificate string
 
-func FetchLogin(client *gqlclient.Client, ctx context.Context) (viewer *User, err error) {
-	op := gqlclient.NewOperation("query fetchLogin {\n\tviewer {\n\t\tlogin\n\t}\n}\n")
+func FetchLogin(client *gqlclient.Client, ctx context.Context) (rateLimit *RateLimit, viewer *User, err error) {
+	op := gqlclient.NewOperation("query fetchLogin {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\tviewer {\n\t\tlogin\n\t}\n}\n")
 	var respData struct {
-		Viewer *User
+		RateLimit *RateLimit
+		Viewer    *User
 	}
 	err = client.Execute(ctx, op, &respData)
-	return respData.Viewer, err
+	return respData.RateLimit, respData.Viewer, err
 }
 
-func FetchPullRequests(client *gqlclient.Client, ctx context.Context, owner string, name string, limit int32, states []PullRequestState) (repository *Repository, err error) {
-	op := gqlclient.NewOperation("query fetchPullRequests ($owner: String!, $name: String!, $limit: Int!, $states: [PullRequestState!]) {\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequests(first: $limit, states: $states, orderBy: {field:CREATED_AT,direction:DESC}) {\n\t\t\tedges {\n\t\t\t\tnode {\n\t\t\t\t\tauthor {\n\t\t\t\t\t\tlogin\n\t\t\t\t\t}\n\t\t\t\t\tbaseRefOid\n\t\t\t\t\tnumber\n\t\t\t\t\ttitle\n\t\t\t\t\tcreatedAt\n\t\t\t\t\theadRef {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\tcomments {\n\t\t\t\t\t\ttotalCount\n\t\t\t\t\t}\n\t\t\t\t\tcommits(last: 1) {\n\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\tcommit {\n\t\t\t\t\t\t\t\tstatus {\n\t\t\t\t\t\t\t\t\tstate\n\t\t\t\t\t\t\t\t\tcontexts {\n\t\t\t\t\t\t\t\t\t\tstate\n\t\t\t\t\t\t\t\t\t\tcontext\n\t\t\t\t\t\t\t\t\t\tdescription\n\t\t\t\t\t\t\t\t\t\ttargetUrl\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t\trepository {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\treviews(first: 100) {\n\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\tcomments {\n\t\t\t\t\t\t\t\t\ttotalCount\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+func FetchPullRequests(client *gqlclient.Client, ctx context.Context, owner string, name string, limit int32, states []PullRequestState, after *string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchPullRequests ($owner: String!, $name: String!, $limit: Int!, $states: [PullRequestState!], $after: String) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequests(first: $limit, after: $after, states: $states, orderBy: {field:CREATED_AT,direction:DESC}) {\n\t\t\tpageInfo {\n\t\t\t\thasNextPage\n\t\t\t\tendCursor\n\t\t\t}\n\t\t\tedges {\n\t\t\t\tnode {\n\t\t\t\t\tauthor {\n\t\t\t\t\t\tlogin\n\t\t\t\t\t}\n\t\t\t\t\tbaseRefOid\n\t\t\t\t\tnumber\n\t\t\t\t\ttitle\n\t\t\t\t\tcreatedAt\n\t\t\t\t\theadRef {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\tcomments {\n\t\t\t\t\t\ttotalCount\n\t\t\t\t\t}\n\t\t\t\t\tcommits(last: 1) {\n\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\tcommit {\n\t\t\t\t\t\t\t\tstatusCheckRollup {\n\t\t\t\t\t\t\t\t\tstate\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t\trepository {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\treviews(first: 100) {\n\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\tcomments {\n\t\t\t\t\t\t\t\t\ttotalCount\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
 	op.Var("owner", owner)
 	op.Var("name", name)
 	op.Var("limit", limit)
 	op.Var("states", states)
+	op.Var("after", after)
 	var respData struct {
+		RateLimit  *RateLimit
 		Repository *Repository
 	}
 	err = client.Execute(ctx, op, &respData)
-	return respData.Repository, err
+	return respData.RateLimit, respData.Repository, err
 }
 
-func FetchPullRequestID(client *gqlclient.Client, ctx context.Context, owner string, name string, number int32) (repository *Repository, err error) {
-	op := gqlclient.NewOperation("query fetchPullRequestID ($owner: String!, $name: String!, $number: Int!) {\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\tid\n\t\t}\n\t}\n}\n")
+func FetchPullRequestID(client *gqlclient.Client, ctx context.Context, owner string, name string, number int32) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchPullRequestID ($owner: String!, $name: String!, $number: Int!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\tid\n\t\t}\n\t}\n}\n")
 	op.Var("owner", owner)
 	op.Var("name", name)
 	op.Var("number", number)
 	var respData struct {
+		RateLimit  *RateLimit
 		Repository *Repository
 	}
 	err = client.Execute(ctx, op, &respData)
-	return respData.Repository, err
+	return respData.RateLimit, respData.Repository, err
 }
 
-func FetchMyPullRequests(client *gqlclient.Client, ctx context.Context, limit int32) (viewer *User, err error) {
-	op := gqlclient.NewOperation("query fetchMyPullRequests ($limit: Int!) {\n\tviewer {\n\t\tpullRequests(first: $limit, states: OPEN, orderBy: {field:CREATED_AT,direction:DESC}) {\n\t\t\tedges {\n\t\t\t\tnode {\n\t\t\t\t\tauthor {\n\t\t\t\t\t\tlogin\n\t\t\t\t\t}\n\t\t\t\t\tnumber\n\t\t\t\t\ttitle\n\t\t\t\t\tcreatedAt\n\t\t\t\t\theadRef {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\tcomments {\n\t\t\t\t\t\ttotalCount\n\t\t\t\t\t}\n\t\t\t\t\trepository {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\treviews(first: 100) {\n\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\tcomments {\n\t\t\t\t\t\t\t\t\ttotalCount\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+func FetchMyPullRequests(client *gqlclient.Client, ctx context.Context, limit int32, after *string) (rateLimit *RateLimit, viewer *User, err error) {
+	op := gqlclient.NewOperation("query fetchMyPullRequests ($limit: Int!, $after: String) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\tviewer {\n\t\tpullRequests(first: $limit, after: $after, states: OPEN, orderBy: {field:CREATED_AT,direction:DESC}) {\n\t\t\tpageInfo {\n\t\t\t\thasNextPage\n\t\t\t\tendCursor\n\t\t\t}\n\t\t\tedges {\n\t\t\t\tnode {\n\t\t\t\t\tauthor {\n\t\t\t\t\t\tlogin\n\t\t\t\t\t}\n\t\t\t\t\tnumber\n\t\t\t\t\ttitle\n\t\t\t\t\tcreatedAt\n\t\t\t\t\theadRef {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\tcomments {\n\t\t\t\t\t\ttotalCount\n\t\t\t\t\t}\n\t\t\t\t\trepository {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\treviews(first: 100) {\n\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\tcomments {\n\t\t\t\t\t\t\t\t\ttotalCount\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
 	op.Var("limit", limit)
+	op.Var("after", after)
 	var respData struct {
-		Viewer *User
+		RateLimit *RateLimit
+		Viewer    *User
 	}
 	err = client.Execute(ctx, op, &respData)
-	return respData.Viewer, err
+	return respData.RateLimit, respData.Viewer, err
 }
 
-func FetchMyPullRequestReviewQueue(client *gqlclient.Client, ctx context.Context, query string, limit int32) (search *SearchResultItemConnection, err error) {
-	op := gqlclient.NewOperation("query fetchMyPullRequestReviewQueue ($query: String!, $limit: Int!) {\n\tsearch(query: $query, type: ISSUE, first: $limit) {\n\t\tedges {\n\t\t\tnode {\n\t\t\t\t... on PullRequest {\n\t\t\t\t\t__typename\n\t\t\t\t\tauthor {\n\t\t\t\t\t\tlogin\n\t\t\t\t\t}\n\t\t\t\t\tnumber\n\t\t\t\t\ttitle\n\t\t\t\t\tcreatedAt\n\t\t\t\t\theadRef {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\tcomments {\n\t\t\t\t\t\ttotalCount\n\t\t\t\t\t}\n\t\t\t\t\trepository {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\treviews(first: 100) {\n\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\tcomments {\n\t\t\t\t\t\t\t\t\ttotalCount\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t\ttimelineItems(last: 10) {\n\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\t__typename\n\t\t\t\t\t\t\t... on ReviewRequestedEvent {\n\t\t\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\t\t\trequestedReviewer {\n\t\t\t\t\t\t\t\t\t... on User {\n\t\t\t\t\t\t\t\t\t\t__typename\n\t\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+func FetchMyPullRequestReviewQueue(client *gqlclient.Client, ctx context.Context, query string, limit int32, after *string) (rateLimit *RateLimit, search *SearchResultItemConnection, err error) {
+	op := gqlclient.NewOperation("query fetchMyPullRequestReviewQueue ($query: String!, $limit: Int!, $after: String) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\tsearch(query: $query, type: ISSUE, first: $limit, after: $after) {\n\t\tpageInfo {\n\t\t\thasNextPage\n\t\t\tendCursor\n\t\t}\n\t\tedges {\n\t\t\tnode {\n\t\t\t\t... on PullRequest {\n\t\t\t\t\t__typename\n\t\t\t\t\tauthor {\n\t\t\t\t\t\tlogin\n\t\t\t\t\t}\n\t\t\t\t\tnumber\n\t\t\t\t\ttitle\n\t\t\t\t\tcreatedAt\n\t\t\t\t\theadRef {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\tcomments {\n\t\t\t\t\t\ttotalCount\n\t\t\t\t\t}\n\t\t\t\t\trepository {\n\t\t\t\t\t\tname\n\t\t\t\t\t}\n\t\t\t\t\treviews(first: 100) {\n\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\tcomments {\n\t\t\t\t\t\t\t\t\ttotalCount\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t\ttimelineItems(last: 10) {\n\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\t__typename\n\t\t\t\t\t\t\t... on ReviewRequestedEvent {\n\t\t\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\t\t\trequestedReviewer {\n\t\t\t\t\t\t\t\t\t... on User {\n\t\t\t\t\t\t\t\t\t\t__typename\n\t\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
 	op.Var("query", query)
 	op.Var("limit", limit)
+	op.Var("after", after)
 	var respData struct {
-		Search *SearchResultItemConnection
+		RateLimit *RateLimit
+		Search    *SearchResultItemConnection
 	}
 	err = client.Execute(ctx, op, &respData)
-	return respData.Search, err
+	return respData.RateLimit, respData.Search, err
 }
 
-func FetchConversation(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string) (repository *Repository, err error) {
-	op := gqlclient.NewOperation("query fetchConversation ($number: Int!, $owner: String!, $name: String!) {\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\ttitle\n\t\t\tnumber\n\t\t\tbody\n\t\t\tauthor {\n\t\t\t\tlogin\n\t\t\t\t... on User {\n\t\t\t\t\t__typename\n\t\t\t\t\tname\n\t\t\t\t}\n\t\t\t}\n\t\t\tcomments(first: 100) {\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t\tcreatedAt\n\t\t\tnumber\n\t\t\trepository {\n\t\t\t\tname\n\t\t\t}\n\t\t\treviews(first: 100) {\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\tcomments(first: 100) {\n\t\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\t\t\t\tdiffHunk\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+func FetchConversation(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchConversation ($number: Int!, $owner: String!, $name: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\ttitle\n\t\t\tnumber\n\t\t\tbody\n\t\t\tauthor {\n\t\t\t\tlogin\n\t\t\t\t... on User {\n\t\t\t\t\t__typename\n\t\t\t\t\tname\n\t\t\t\t}\n\t\t\t}\n\t\t\tcomments(first: 100) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t\tcreatedAt\n\t\t\tnumber\n\t\t\trepository {\n\t\t\t\tname\n\t\t\t}\n\t\t\treviews(first: 100) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tstate\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t\treviewThreads(first: 100) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tid\n\t\t\t\t\t\tisResolved\n\t\t\t\t\t\tisOutdated\n\t\t\t\t\t\tpath\n\t\t\t\t\t\tline\n\t\t\t\t\t\toriginalLine\n\t\t\t\t\t\tdiffSide\n\t\t\t\t\t\tcomments(first: 100) {\n\t\t\t\t\t\t\tpageInfo {\n\t\t\t\t\t\t\t\thasNextPage\n\t\t\t\t\t\t\t\tendCursor\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\t\t\t\tdiffHunk\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
 	op.Var("number", number)
 	op.Var("owner", owner)
 	op.Var("name", name)
 	var respData struct {
+		RateLimit  *RateLimit
 		Repository *Repository
 	}
 	err = client.Execute(ctx, op, &respData)
-	return respData.Repository, err
+	return respData.RateLimit, respData.Repository, err
+}
+
+func FetchIssueComments(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string, after string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchIssueComments ($number: Int!, $owner: String!, $name: String!, $after: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\tcomments(first: 100, after: $after) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("number", number)
+	op.Var("owner", owner)
+	op.Var("name", name)
+	op.Var("after", after)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
+
+func FetchReviews(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string, after string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchReviews ($number: Int!, $owner: String!, $name: String!, $after: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\treviews(first: 100, after: $after) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tstate\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("number", number)
+	op.Var("owner", owner)
+	op.Var("name", name)
+	op.Var("after", after)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
+
+func FetchReviewThreads(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string, after string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchReviewThreads ($number: Int!, $owner: String!, $name: String!, $after: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\treviewThreads(first: 100, after: $after) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tid\n\t\t\t\t\t\tisResolved\n\t\t\t\t\t\tisOutdated\n\t\t\t\t\t\tpath\n\t\t\t\t\t\tline\n\t\t\t\t\t\toriginalLine\n\t\t\t\t\t\tdiffSide\n\t\t\t\t\t\tcomments(first: 100) {\n\t\t\t\t\t\t\tpageInfo {\n\t\t\t\t\t\t\t\thasNextPage\n\t\t\t\t\t\t\t\tendCursor\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tedges {\n\t\t\t\t\t\t\t\tnode {\n\t\t\t\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t\tbody\n\t\t\t\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\t\t\t\tdiffHunk\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("number", number)
+	op.Var("owner", owner)
+	op.Var("name", name)
+	op.Var("after", after)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
+
+func FetchThreadComments(client *gqlclient.Client, ctx context.Context, id string, after string) (rateLimit *RateLimit, node *Node, err error) {
+	op := gqlclient.NewOperation("query fetchThreadComments ($id: ID!, $after: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\tnode(id: $id) {\n\t\t... on PullRequestReviewThread {\n\t\t\t__typename\n\t\t\tcomments(first: 100, after: $after) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\tauthor {\n\t\t\t\t\t\t\tlogin\n\t\t\t\t\t\t}\n\t\t\t\t\t\tbody\n\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\tdiffHunk\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("id", id)
+	op.Var("after", after)
+	var respData struct {
+		RateLimit *RateLimit
+		Node      *Node
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Node, err
 }
 
 func MarkAsReady(client *gqlclient.Client, ctx context.Context, input MarkPullRequestReadyForReviewInput) (markPullRequestReadyForReview *MarkPullRequestReadyForReviewPayload, err error) {
@@ -31125,3 +31188,126 @@ func MarkAsReady(client *gqlclient.Client, ctx context.Context, input MarkPullRe
 	err = client.Execute(ctx, op, &respData)
 	return respData.MarkPullRequestReadyForReview, err
 }
+
+func FetchReviewIDs(client *gqlclient.Client, ctx context.Context, owner string, name string, number int32, after *string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchReviewIDs ($owner: String!, $name: String!, $number: Int!, $after: String) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\treviews(first: 100, after: $after) {\n\t\t\t\tpageInfo {\n\t\t\t\t\thasNextPage\n\t\t\t\t\tendCursor\n\t\t\t\t}\n\t\t\t\tedges {\n\t\t\t\t\tnode {\n\t\t\t\t\t\tid\n\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("owner", owner)
+	op.Var("name", name)
+	op.Var("number", number)
+	op.Var("after", after)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
+
+func DismissReview(client *gqlclient.Client, ctx context.Context, input DismissPullRequestReviewInput) (dismissPullRequestReview *DismissPullRequestReviewPayload, err error) {
+	op := gqlclient.NewOperation("mutation dismissReview ($input: DismissPullRequestReviewInput!) {\n\tdismissPullRequestReview(input: $input) {\n\t\tpullRequestReview {\n\t\t\tid\n\t\t}\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		DismissPullRequestReview *DismissPullRequestReviewPayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.DismissPullRequestReview, err
+}
+
+func ResolveReviewThread(client *gqlclient.Client, ctx context.Context, input ResolveReviewThreadInput) (resolveReviewThread *ResolveReviewThreadPayload, err error) {
+	op := gqlclient.NewOperation("mutation resolveReviewThread ($input: ResolveReviewThreadInput!) {\n\tresolveReviewThread(input: $input) {\n\t\tthread {\n\t\t\tid\n\t\t}\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		ResolveReviewThread *ResolveReviewThreadPayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.ResolveReviewThread, err
+}
+
+func UnresolveReviewThread(client *gqlclient.Client, ctx context.Context, input UnresolveReviewThreadInput) (unresolveReviewThread *UnresolveReviewThreadPayload, err error) {
+	op := gqlclient.NewOperation("mutation unresolveReviewThread ($input: UnresolveReviewThreadInput!) {\n\tunresolveReviewThread(input: $input) {\n\t\tthread {\n\t\t\tid\n\t\t}\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		UnresolveReviewThread *UnresolveReviewThreadPayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.UnresolveReviewThread, err
+}
+
+func AddReviewThreadReply(client *gqlclient.Client, ctx context.Context, input AddPullRequestReviewThreadReplyInput) (addPullRequestReviewThreadReply *AddPullRequestReviewThreadReplyPayload, err error) {
+	op := gqlclient.NewOperation("mutation addReviewThreadReply ($input: AddPullRequestReviewThreadReplyInput!) {\n\taddPullRequestReviewThreadReply(input: $input) {\n\t\tcomment {\n\t\t\tdatabaseId\n\t\t}\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		AddPullRequestReviewThreadReply *AddPullRequestReviewThreadReplyPayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.AddPullRequestReviewThreadReply, err
+}
+
+func FetchMergeState(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchMergeState ($number: Int!, $owner: String!, $name: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\tid\n\t\t\tnumber\n\t\t\tstate\n\t\t\tisDraft\n\t\t\tmergeable\n\t\t\tmergeStateStatus\n\t\t\treviewDecision\n\t\t\theadRefOid\n\t\t\theadRef {\n\t\t\t\tid\n\t\t\t}\n\t\t\tcommits(last: 1) {\n\t\t\t\tnodes {\n\t\t\t\t\tcommit {\n\t\t\t\t\t\tstatusCheckRollup {\n\t\t\t\t\t\t\tstate\n\t\t\t\t\t\t\tcontexts(first: 100) {\n\t\t\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\t\t\t__typename\n\t\t\t\t\t\t\t\t\t... on CheckRun {\n\t\t\t\t\t\t\t\t\t\tname\n\t\t\t\t\t\t\t\t\t\tstatus\n\t\t\t\t\t\t\t\t\t\tconclusion\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t\t... on StatusContext {\n\t\t\t\t\t\t\t\t\t\tcontext\n\t\t\t\t\t\t\t\t\t\tstate\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("number", number)
+	op.Var("owner", owner)
+	op.Var("name", name)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
+
+func MergePullRequest(client *gqlclient.Client, ctx context.Context, input MergePullRequestInput) (mergePullRequest *MergePullRequestPayload, err error) {
+	op := gqlclient.NewOperation("mutation mergePullRequest ($input: MergePullRequestInput!) {\n\tmergePullRequest(input: $input) {\n\t\tpullRequest {\n\t\t\tmerged\n\t\t}\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		MergePullRequest *MergePullRequestPayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.MergePullRequest, err
+}
+
+func EnableAutoMerge(client *gqlclient.Client, ctx context.Context, input EnablePullRequestAutoMergeInput) (enablePullRequestAutoMerge *EnablePullRequestAutoMergePayload, err error) {
+	op := gqlclient.NewOperation("mutation enableAutoMerge ($input: EnablePullRequestAutoMergeInput!) {\n\tenablePullRequestAutoMerge(input: $input) {\n\t\tpullRequest {\n\t\t\tnumber\n\t\t}\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		EnablePullRequestAutoMerge *EnablePullRequestAutoMergePayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.EnablePullRequestAutoMerge, err
+}
+
+func DeleteRef(client *gqlclient.Client, ctx context.Context, input DeleteRefInput) (deleteRef *DeleteRefPayload, err error) {
+	op := gqlclient.NewOperation("mutation deleteRef ($input: DeleteRefInput!) {\n\tdeleteRef(input: $input) {\n\t\tclientMutationId\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		DeleteRef *DeleteRefPayload
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.DeleteRef, err
+}
+
+func FetchChecks(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchChecks ($number: Int!, $owner: String!, $name: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\tcommits(last: 1) {\n\t\t\t\tnodes {\n\t\t\t\t\tcommit {\n\t\t\t\t\t\toid\n\t\t\t\t\t\tstatus {\n\t\t\t\t\t\t\tcontexts {\n\t\t\t\t\t\t\t\tstate\n\t\t\t\t\t\t\t\tcontext\n\t\t\t\t\t\t\t\tdescription\n\t\t\t\t\t\t\t\ttargetUrl\n\t\t\t\t\t\t\t\tcreatedAt\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t\tcheckSuites(first: 100) {\n\t\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\t\t\tapp {\n\t\t\t\t\t\t\t\t\tname\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tworkflowRun {\n\t\t\t\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\t\t\t\tworkflow {\n\t\t\t\t\t\t\t\t\t\tname\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\tcheckRuns(first: 100) {\n\t\t\t\t\t\t\t\t\tnodes {\n\t\t\t\t\t\t\t\t\t\tdatabaseId\n\t\t\t\t\t\t\t\t\t\tname\n\t\t\t\t\t\t\t\t\t\tstatus\n\t\t\t\t\t\t\t\t\t\tconclusion\n\t\t\t\t\t\t\t\t\t\tstartedAt\n\t\t\t\t\t\t\t\t\t\tcompletedAt\n\t\t\t\t\t\t\t\t\t\tdetailsUrl\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("number", number)
+	op.Var("owner", owner)
+	op.Var("name", name)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
+
+func FetchPullRequestHead(client *gqlclient.Client, ctx context.Context, number int32, owner string, name string) (rateLimit *RateLimit, repository *Repository, err error) {
+	op := gqlclient.NewOperation("query fetchPullRequestHead ($number: Int!, $owner: String!, $name: String!) {\n\trateLimit {\n\t\tcost\n\t\tlimit\n\t\tremaining\n\t\tresetAt\n\t}\n\trepository(owner: $owner, name: $name) {\n\t\tpullRequest(number: $number) {\n\t\t\tnumber\n\t\t\tstate\n\t\t\theadRefName\n\t\t\tisCrossRepository\n\t\t\tmaintainerCanModify\n\t\t\theadRepository {\n\t\t\t\tname\n\t\t\t\turl\n\t\t\t\tsshUrl\n\t\t\t\towner {\n\t\t\t\t\tlogin\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("number", number)
+	op.Var("owner", owner)
+	op.Var("name", name)
+	var respData struct {
+		RateLimit  *RateLimit
+		Repository *Repository
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.RateLimit, respData.Repository, err
+}
@@ -0,0 +1,175 @@
+package re
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Job is a GitHub Actions job as returned by the REST API. The ID of a job is
+// the same as the ID of the check run it reports to.
+type Job struct {
+	ID         int       `json:"id"`
+	RunID      int       `json:"run_id"`
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HTMLURL    string    `json:"html_url"`
+	Steps      []JobStep `json:"steps"`
+}
+
+// JobStep is a single step of a GitHub Actions job.
+type JobStep struct {
+	Number      int       `json:"number"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Completed reports whether the job has finished running.
+func (j *Job) Completed() bool {
+	return j.Status == "completed"
+}
+
+// FailedStep returns the first step of the job that failed, or nil if there
+// is none.
+func (j *Job) FailedStep() *JobStep {
+	for i, step := range j.Steps {
+		if step.Conclusion == "failure" {
+			return &j.Steps[i]
+		}
+	}
+	return nil
+}
+
+// FetchJob returns a GitHub Actions job including its steps.
+func (c *Client) FetchJob(ctx context.Context, owner, repository string, id int) (*Job, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/jobs/%d", c.endpoint, owner, repository, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("FetchJob: check run %d is not a GitHub Actions job", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+	var job Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FetchJobLog downloads the plain text log of a completed GitHub Actions job.
+func (c *Client) FetchJobLog(ctx context.Context, owner, repository string, id int) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/jobs/%d/logs", c.endpoint, owner, repository, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	// The API redirects to a short-lived, pre-signed URL which must not
+	// receive the access token, so the redirect is followed without it.
+	client := *c.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusFound {
+		location := resp.Header.Get("Location")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FetchJobLog: %w", newAPIError(resp))
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// logTail returns up to n lines of a job log leading up to the last error,
+// or the end of the log if it contains no error. The timestamps prefixing
+// every line are removed. If n is zero, all lines up to the error are
+// returned.
+func logTail(log string, n int) []string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	end := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], "##[error]") {
+			end = i + 1
+			break
+		}
+	}
+	start := 0
+	if n > 0 && end-n > 0 {
+		start = end - n
+	}
+	tail := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		tail = append(tail, trimLogTimestamp(line))
+	}
+	return tail
+}
+
+// stepLog returns the lines of a job log written while the given step ran.
+// The log does not mark where a step begins, so the lines are selected by
+// their timestamps, which are more precise than those of the step.
+func stepLog(log string, step *JobStep) string {
+	if step.StartedAt.IsZero() {
+		return log
+	}
+	start := step.StartedAt.Truncate(time.Second)
+	end := step.CompletedAt.Truncate(time.Second).Add(time.Second)
+	var (
+		b    strings.Builder
+		keep bool
+	)
+	for _, line := range strings.SplitAfter(log, "\n") {
+		// Lines without a timestamp belong to the line before them.
+		timestamp, _, _ := strings.Cut(line, " ")
+		if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			keep = !t.Before(start) && (step.CompletedAt.IsZero() || t.Before(end))
+		}
+		if keep {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// trimLogTimestamp removes the timestamp such as
+// "2006-01-02T15:04:05.0000000Z " that prefixes each line of a job log.
+func trimLogTimestamp(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	timestamp, rest, ok := strings.Cut(line, " ")
+	if !ok || len(timestamp) < len("2006-01-02T15:04:05Z") || timestamp[4] != '-' || timestamp[10] != 'T' {
+		return line
+	}
+	return rest
+}
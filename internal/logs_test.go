@@ -0,0 +1,136 @@
+package re
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub/graph/model"
+)
+
+func TestLogTail(t *testing.T) {
+	log := "2024-05-01T10:00:00.0000000Z ##[group]Run go test ./...\n" +
+		"2024-05-01T10:00:01.0000000Z --- FAIL: TestFoo (0.00s)\n" +
+		"2024-05-01T10:00:01.0000000Z FAIL\r\n" +
+		"2024-05-01T10:00:02.0000000Z ##[error]Process completed with exit code 1.\n" +
+		"2024-05-01T10:00:03.0000000Z Post job cleanup.\n"
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{
+			name: "tail",
+			n:    2,
+			want: []string{"FAIL", "##[error]Process completed with exit code 1."},
+		},
+		{
+			name: "all",
+			want: []string{
+				"##[group]Run go test ./...",
+				"--- FAIL: TestFoo (0.00s)",
+				"FAIL",
+				"##[error]Process completed with exit code 1.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(logTail(log, tt.n), tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFetchJobLogError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"viewer":{"login":"foo"}}}`))
+	})
+	mux.HandleFunc("GET /repos/foo/bar/actions/jobs/1/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		w.Write([]byte(`{"message":"Job logs have expired"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(t.Context(), Config{Endpoint: server.URL, RESTEndpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.FetchJobLog(t.Context(), "foo", "bar", 1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T, want *APIError", err)
+	}
+	if diff := cmp.Diff("Job logs have expired", apiErr.Message); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestPrintLogsFollow(t *testing.T) {
+	restore := logPollInterval
+	logPollInterval = time.Millisecond
+	t.Cleanup(func() { logPollInterval = restore })
+
+	// The job is polled three times: the log grows while it is in progress
+	// and the job fails at the second step once it completed.
+	logs := []string{
+		"2024-05-01T10:00:00.1000000Z ##[group]Run actions/checkout\n" +
+			"2024-05-01T10:00:01.2000000Z Checked out\n",
+		"2024-05-01T10:00:00.1000000Z ##[group]Run actions/checkout\n" +
+			"2024-05-01T10:00:01.2000000Z Checked out\n" +
+			"2024-05-01T10:00:02.3000000Z ##[group]Run go test ./...\n" +
+			"2024-05-01T10:00:03.4000000Z --- FAIL: TestFoo (0.00s)\n" +
+			"2024-05-01T10:00:04.5000000Z ##[error]Process completed with exit code 1.\n" +
+			"2024-05-01T10:00:05.6000000Z Post job cleanup.\n",
+	}
+	jobs := []string{
+		`{"id": 1, "name": "test", "status": "in_progress"}`,
+		`{"id": 1, "name": "test", "status": "in_progress"}`,
+		`{"id": 1, "name": "test", "status": "completed", "conclusion": "failure", "steps": [
+			{"number": 1, "name": "checkout", "status": "completed", "conclusion": "success", "started_at": "2024-05-01T10:00:00Z", "completed_at": "2024-05-01T10:00:01Z"},
+			{"number": 2, "name": "go test", "status": "completed", "conclusion": "failure", "started_at": "2024-05-01T10:00:02Z", "completed_at": "2024-05-01T10:00:04Z"},
+			{"number": 3, "name": "cleanup", "status": "completed", "conclusion": "success", "started_at": "2024-05-01T10:00:05Z", "completed_at": "2024-05-01T10:00:05Z"}
+		]}`,
+	}
+	var polls int
+	fake := fakegithub.New(t, fakegithub.WithCheckRun("test", 1, model.CheckConclusionStateFailure))
+	fake.HandleFunc("GET /repos/foo/test-repo/actions/jobs/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(jobs[min(polls, len(jobs)-1)]))
+		polls++
+	})
+	fake.HandleFunc("GET /repos/foo/test-repo/actions/jobs/1/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(logs[min(polls-1, len(logs)-1)]))
+	})
+	client, err := NewClient(t.Context(), Config{Endpoint: fake.URL, RESTEndpoint: fake.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	command := &Command{client: client, out: &buf, org: "foo", name: "test-repo"}
+	if err := command.PrintLogs(t.Context(), 1, "", true, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := "##[group]Run actions/checkout\n" +
+		"Checked out\n" +
+		"##[group]Run go test ./...\n" +
+		"--- FAIL: TestFoo (0.00s)\n" +
+		"##[error]Process completed with exit code 1.\n" +
+		"Post job cleanup.\n" +
+		"test: failure at step \"go test\"\n" +
+		"##[group]Run go test ./...\n" +
+		"--- FAIL: TestFoo (0.00s)\n" +
+		"##[error]Process completed with exit code 1.\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
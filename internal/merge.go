@@ -0,0 +1,107 @@
+package re
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeOptions configures how a pull request is merged.
+type MergeOptions struct {
+	Method PullRequestMergeMethod
+	// Auto enables auto-merge instead of merging right away, so that the pull
+	// request is merged once all requirements are met.
+	Auto bool
+	// DeleteBranch deletes the head branch after merging.
+	DeleteBranch bool
+}
+
+// mergeBlockers returns the reasons why a pull request cannot be merged, or
+// nil if it can. With auto-merge, pending requirements such as running status
+// checks or missing approvals do not block, since GitHub merges the pull
+// request once they are met.
+func mergeBlockers(pr *PullRequest, auto bool) []string {
+	switch pr.State {
+	case PullRequestStateMerged:
+		return []string{"it is already merged"}
+	case PullRequestStateClosed:
+		return []string{"it is closed"}
+	}
+	if pr.IsDraft {
+		return []string{"it is a draft, mark it as ready first"}
+	}
+
+	var blockers []string
+	switch pr.Mergeable {
+	case MergeableStateConflicting:
+		blockers = append(blockers, "it has conflicts with the base branch")
+	case MergeableStateUnknown:
+		if !auto {
+			blockers = append(blockers, "GitHub is still computing whether it can be merged, try again shortly")
+		}
+	}
+	if pr.MergeStateStatus == MergeStateStatusBehind && !auto {
+		blockers = append(blockers, "the head branch is behind the base branch")
+	}
+
+	if pr.ReviewDecision != nil && !auto {
+		switch *pr.ReviewDecision {
+		case PullRequestReviewDecisionChangesRequested:
+			blockers = append(blockers, "changes were requested by a reviewer")
+		case PullRequestReviewDecisionReviewRequired:
+			blockers = append(blockers, "it requires an approving review")
+		}
+	}
+
+	for _, check := range rollupChecks(pr) {
+		switch check.Status() {
+		case StatusStateSuccess:
+		case StatusStatePending:
+			if !auto {
+				blockers = append(blockers, fmt.Sprintf("status check %s is pending", check.Name))
+			}
+		default:
+			blockers = append(blockers, fmt.Sprintf("status check %s failed", check.Name))
+		}
+	}
+	return blockers
+}
+
+// rollupChecks returns the check runs and commit statuses of the head commit
+// of a pull request, as summarized by its status check rollup.
+func rollupChecks(pr *PullRequest) []Check {
+	if pr.Commits == nil {
+		return nil
+	}
+	var checks []Check
+	for _, node := range pr.Commits.Nodes {
+		rollup := node.Commit.StatusCheckRollup
+		if rollup == nil || rollup.Contexts == nil {
+			continue
+		}
+		for _, context := range rollup.Contexts.Nodes {
+			switch value := context.Value.(type) {
+			case *CheckRun:
+				checks = append(checks, Check{Name: value.Name, State: checkRunState(value.Status, value.Conclusion)})
+			case *StatusContext:
+				checks = append(checks, Check{Name: value.Context, State: string(value.State)})
+			}
+		}
+	}
+	return checks
+}
+
+// MergeBlockedError is returned when a pull request does not meet the
+// requirements to be merged.
+type MergeBlockedError struct {
+	Number   int
+	Blockers []string
+}
+
+func (e *MergeBlockedError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "cannot merge #%d:", e.Number)
+	for _, blocker := range e.Blockers {
+		fmt.Fprintf(&b, "\n  - %s", blocker)
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package re
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeBlockers(t *testing.T) {
+	withChecks := func(values ...StatusCheckRollupContextValue) *PullRequestCommitConnection {
+		contexts := &StatusCheckRollupContextConnection{}
+		for _, value := range values {
+			contexts.Nodes = append(contexts.Nodes, &StatusCheckRollupContext{Value: value})
+		}
+		return &PullRequestCommitConnection{
+			Nodes: []*PullRequestCommit{{Commit: &Commit{StatusCheckRollup: &StatusCheckRollup{Contexts: contexts}}}},
+		}
+	}
+	success, failure := CheckConclusionStateSuccess, CheckConclusionStateFailure
+	reviewRequired := PullRequestReviewDecisionReviewRequired
+	tests := []struct {
+		name string
+		pr   *PullRequest
+		auto bool
+		want []string
+	}{
+		{
+			name: "mergeable",
+			pr: &PullRequest{
+				State:     PullRequestStateOpen,
+				Mergeable: MergeableStateMergeable,
+				Commits: withChecks(
+					&StatusContext{Context: "ci/lint", State: StatusStateSuccess},
+					&CheckRun{Name: "test", Status: CheckStatusStateCompleted, Conclusion: &success},
+				),
+			},
+		},
+		{
+			name: "draft",
+			pr:   &PullRequest{State: PullRequestStateOpen, IsDraft: true},
+			want: []string{"it is a draft, mark it as ready first"},
+		},
+		{
+			name: "blocked",
+			pr: &PullRequest{
+				State:          PullRequestStateOpen,
+				Mergeable:      MergeableStateConflicting,
+				ReviewDecision: &reviewRequired,
+				Commits: withChecks(
+					&StatusContext{Context: "ci/lint", State: StatusStatePending},
+					&CheckRun{Name: "test", Status: CheckStatusStateCompleted, Conclusion: &failure},
+				),
+			},
+			want: []string{
+				"it has conflicts with the base branch",
+				"it requires an approving review",
+				"status check ci/lint is pending",
+				"status check test failed",
+			},
+		},
+		{
+			name: "auto",
+			pr: &PullRequest{
+				State:          PullRequestStateOpen,
+				Mergeable:      MergeableStateMergeable,
+				ReviewDecision: &reviewRequired,
+				Commits: withChecks(
+					&StatusContext{Context: "ci/lint", State: StatusStatePending},
+					&CheckRun{Name: "test", Status: CheckStatusStateCompleted, Conclusion: &failure},
+				),
+			},
+			auto: true,
+			want: []string{"status check test failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeBlockers(tt.pr, tt.auto)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package re
+
+import (
+	"sort"
+	"time"
+)
+
+// PullRequestSummary is the condensed view of a pull request shown by the
+// listing commands. Its fields form the stable output of --format.
+type PullRequestSummary struct {
+	Number     int         `json:"number"`
+	Title      string      `json:"title"`
+	Author     string      `json:"author"`
+	Repository string      `json:"repository"`
+	HeadRef    string      `json:"headRef,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	Comments   int         `json:"comments"`
+	Status     StatusState `json:"status"`
+	// Participating is set if the viewer has reviewed the pull request.
+	Participating bool `json:"participating"`
+	// AwaitingViewer is set if the viewer participates but somebody else
+	// reviewed last.
+	AwaitingViewer bool `json:"awaitingViewer"`
+	// ViewerIsAuthor is set if the viewer opened the pull request.
+	ViewerIsAuthor bool `json:"viewerIsAuthor"`
+}
+
+func newPullRequestSummary(login string, pr *PullRequest) (PullRequestSummary, error) {
+	createdAt, err := time.Parse(time.RFC3339, string(pr.CreatedAt))
+	if err != nil {
+		return PullRequestSummary{}, err
+	}
+	summary := PullRequestSummary{
+		Number:    int(pr.Number),
+		Title:     pr.Title,
+		CreatedAt: createdAt,
+		Status:    statusCheck(pr),
+	}
+	if pr.Author != nil {
+		summary.Author = pr.Author.Login
+		summary.ViewerIsAuthor = pr.Author.Login == login
+	}
+	if pr.Repository != nil {
+		summary.Repository = pr.Repository.Name
+	}
+	if pr.HeadRef != nil {
+		summary.HeadRef = pr.HeadRef.Name
+	}
+	if pr.Comments != nil {
+		summary.Comments = int(pr.Comments.TotalCount)
+	}
+	if pr.Reviews == nil {
+		return summary, nil
+	}
+	var lastReviewByViewer bool
+	for i, review := range pr.Reviews.Edges {
+		if review.Node.Body != "" {
+			summary.Comments += 1
+		}
+		summary.Comments += int(review.Node.Comments.TotalCount)
+
+		if review.Node.Author != nil && review.Node.Author.Login == login {
+			summary.Participating = true
+			if i == len(pr.Reviews.Edges)-1 {
+				lastReviewByViewer = true
+			}
+		}
+	}
+	summary.AwaitingViewer = summary.Participating && !lastReviewByViewer
+	return summary, nil
+}
+
+// Conversation is a pull request together with its description, the
+// comments on the pull request and the review threads on its diff, each sorted
+// by creation time.
+type Conversation struct {
+	Number     int                    `json:"number"`
+	Title      string                 `json:"title"`
+	Author     string                 `json:"author"`
+	AuthorName string                 `json:"authorName,omitempty"`
+	Body       string                 `json:"body"`
+	CreatedAt  time.Time              `json:"createdAt"`
+	Repository string                 `json:"repository"`
+	Comments   []*ConversationComment `json:"comments"`
+	Threads    []*ReviewThread        `json:"threads"`
+}
+
+// AllComments returns the comments on the pull request and of all review
+// threads in a single slice, sorted by creation time.
+func (c *Conversation) AllComments() []*ConversationComment {
+	comments := append([]*ConversationComment{}, c.Comments...)
+	for _, thread := range c.Threads {
+		comments = append(comments, thread.Comments...)
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments
+}
+
+// ThreadOf returns the review thread containing the comment with the given
+// ID, or nil if there is none.
+func (c *Conversation) ThreadOf(commentID int) *ReviewThread {
+	for _, thread := range c.Threads {
+		for _, comment := range thread.Comments {
+			if comment.ID == commentID {
+				return thread
+			}
+		}
+	}
+	return nil
+}
+
+// ReviewThread is a discussion anchored to a line of the diff. Threads can be
+// resolved once the discussion has concluded, and become outdated once the
+// line they refer to changed.
+type ReviewThread struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Side string `json:"side,omitempty"`
+	// OriginalLine is the line the thread was started on in the diff at the
+	// time, which remains set once the thread is outdated.
+	OriginalLine int                    `json:"originalLine,omitempty"`
+	IsResolved   bool                   `json:"isResolved"`
+	IsOutdated   bool                   `json:"isOutdated"`
+	Comments     []*ConversationComment `json:"comments"`
+}
+
+// Ref returns the short ID to reference the thread on the command line, which
+// is the ID of the comment that started it.
+func (t *ReviewThread) Ref() int {
+	return t.Comments[0].ID
+}
+
+// CreatedAt returns when the thread was started.
+func (t *ReviewThread) CreatedAt() time.Time {
+	return t.Comments[0].CreatedAt
+}
+
+func newConversation(pr *PullRequest) (*Conversation, error) {
+	createdAt, err := time.Parse(time.RFC3339, string(pr.CreatedAt))
+	if err != nil {
+		return nil, err
+	}
+	conversation := &Conversation{
+		Number:    int(pr.Number),
+		Title:     pr.Title,
+		Body:      pr.Body,
+		CreatedAt: createdAt,
+	}
+	if pr.Author != nil {
+		conversation.Author = pr.Author.Login
+		if user, ok := pr.Author.Value.(*User); ok && user.Name != nil {
+			conversation.AuthorName = *user.Name
+		}
+	}
+	if pr.Repository != nil {
+		conversation.Repository = pr.Repository.Name
+	}
+	return conversation, nil
+}
+
+// ConversationComment is a single comment of a pull request conversation:
+// either the body of a review, a comment on a line of the diff or a comment on
+// the pull request itself.
+type ConversationComment struct {
+	ID        int       `json:"id"`
+	ThreadID  string    `json:"threadId,omitempty"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	DiffHunk  string    `json:"diffHunk,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	// Side is the side of the diff the line refers to, see [SideLeft] and
+	// [SideRight].
+	Side         string `json:"side,omitempty"`
+	OriginalLine int    `json:"originalLine,omitempty"`
+	// ReviewState is the state of the review the comment is the body of,
+	// such as APPROVED, and empty for other comments.
+	ReviewState string `json:"reviewState,omitempty"`
+}
+
+func newComment(author, body, diffHunk string, createdAt string) (*ConversationComment, error) {
+	parsed, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return &ConversationComment{
+		Author:    author,
+		Body:      body,
+		CreatedAt: parsed,
+		DiffHunk:  diffHunk,
+	}, nil
+}
+
+// InboxItem is a notification about a pull request the viewer participates
+// in, together with the latest comment that triggered it.
+type InboxItem struct {
+	Reason        string               `json:"reason"`
+	Title         string               `json:"title"`
+	Owner         string               `json:"owner"`
+	Repository    string               `json:"repository"`
+	Number        int                  `json:"number"`
+	UpdatedAt     time.Time            `json:"updatedAt"`
+	LatestComment *ConversationComment `json:"latestComment,omitempty"`
+}
+
+// FileDiff is the patch of a single file changed by a pull request.
+type FileDiff struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previousFilename,omitempty"`
+	Status           string `json:"status"`
+	Patch            string `json:"patch,omitempty"`
+	Changes          int    `json:"changes"`
+}
@@ -0,0 +1,42 @@
+package re
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConversationAllComments(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2006, 1, 2, 15, minute, 0, 0, time.UTC)
+	}
+	conversation := &Conversation{
+		Comments: []*ConversationComment{
+			{ID: 1, Body: "first", CreatedAt: at(1)},
+			{ID: 4, Body: "last", CreatedAt: at(4)},
+		},
+		Threads: []*ReviewThread{
+			{
+				ID: "thread",
+				Comments: []*ConversationComment{
+					{ID: 2, Body: "nit", CreatedAt: at(2)},
+					{ID: 3, Body: "done", CreatedAt: at(3)},
+				},
+			},
+		},
+	}
+	var got []string
+	for _, comment := range conversation.AllComments() {
+		got = append(got, comment.Body)
+	}
+	if diff := cmp.Diff(got, []string{"first", "nit", "done", "last"}); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if thread := conversation.ThreadOf(3); thread == nil || thread.ID != "thread" || thread.Ref() != 2 {
+		t.Errorf("ThreadOf(3) = %+v, want thread started by 2", thread)
+	}
+	if thread := conversation.ThreadOf(1); thread != nil {
+		t.Errorf("ThreadOf(1) = %+v, want nil", thread)
+	}
+}
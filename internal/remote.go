@@ -0,0 +1,255 @@
+package re
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Remote is the GitHub repository a git remote points to.
+type Remote struct {
+	// Host is the host name of the GitHub instance, such as github.com.
+	Host  string
+	Owner string
+	Name  string
+	// RemoteName is the name of the git remote, such as origin.
+	RemoteName string
+}
+
+func (r Remote) String() string {
+	return r.Host + "/" + r.Owner + "/" + r.Name
+}
+
+// LookupRemote returns the repository the git remote with the given name
+// points to. The URL is rewritten according to the url.<base>.insteadOf
+// settings, and host aliases are resolved through ~/.ssh/config.
+func LookupRemote(ctx context.Context, git Git, name string) (Remote, error) {
+	url, err := git.RemoteURL(ctx, name)
+	if err != nil {
+		return Remote{}, fmt.Errorf("LookupRemote: no remote named %q: %w", name, err)
+	}
+	sshHosts, err := loadSSHConfig()
+	if err != nil {
+		return Remote{}, err
+	}
+	remote, err := parseRemote(url, sshHosts)
+	if err != nil {
+		return Remote{}, err
+	}
+	remote.RemoteName = name
+	return remote, nil
+}
+
+// DetectRemotes returns the base repository, which pull requests are opened
+// against, and the head repository, which branches are pushed to. The head
+// defaults to origin. The base defaults to upstream if such a remote exists,
+// as is common when working from a fork, and to the head otherwise.
+func DetectRemotes(ctx context.Context, git Git, base, head string) (Remote, Remote, error) {
+	if head == "" {
+		head = "origin"
+	}
+	headRemote, err := LookupRemote(ctx, git, head)
+	if err != nil {
+		return Remote{}, Remote{}, err
+	}
+	if base == "" {
+		if upstream, err := LookupRemote(ctx, git, "upstream"); err == nil {
+			return upstream, headRemote, nil
+		}
+		return headRemote, headRemote, nil
+	}
+	baseRemote, err := LookupRemote(ctx, git, base)
+	if err != nil {
+		return Remote{}, Remote{}, err
+	}
+	return baseRemote, headRemote, nil
+}
+
+// SameRepository reports whether both remotes point to the same repository.
+// Pull requests from a head remote that does not are cross-repository.
+func (r Remote) SameRepository(other Remote) bool {
+	return strings.EqualFold(r.Host, other.Host) &&
+		strings.EqualFold(r.Owner, other.Owner) &&
+		strings.EqualFold(r.Name, other.Name)
+}
+
+// parseRemote parses the URL of a remote, which is either a URL with the
+// https, http, ssh or git scheme or an scp-like address such as
+// git@github.com:owner/name.git. The owner and name are the last two elements
+// of the path, GitHub Enterprise instances served below a path prefix are
+// supported that way.
+func parseRemote(rawURL string, sshHosts sshConfig) (Remote, error) {
+	var host, repoPath string
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parseRemote: %w", err)
+		}
+		switch u.Scheme {
+		case "https", "http", "git":
+			host = u.Hostname()
+		case "ssh", "git+ssh", "ssh+git":
+			host = sshHosts.hostName(u.Hostname())
+		default:
+			return Remote{}, fmt.Errorf("parseRemote: unsupported scheme %q: %s", u.Scheme, rawURL)
+		}
+		repoPath = u.Path
+	} else {
+		// An scp-like address has a colon before the first slash, anything
+		// else is a local path.
+		address, p, ok := strings.Cut(rawURL, ":")
+		if !ok || strings.Contains(address, "/") {
+			return Remote{}, fmt.Errorf("parseRemote: not a GitHub remote: %s", rawURL)
+		}
+		if i := strings.LastIndex(address, "@"); i >= 0 {
+			address = address[i+1:]
+		}
+		host = sshHosts.hostName(address)
+		repoPath = p
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	elems := strings.Split(repoPath, "/")
+	if host == "" || len(elems) < 2 || elems[len(elems)-2] == "" || elems[len(elems)-1] == "" {
+		return Remote{}, fmt.Errorf("parseRemote: expected host/owner/name: %s", rawURL)
+	}
+	return Remote{
+		Host:  strings.ToLower(host),
+		Owner: elems[len(elems)-2],
+		Name:  elems[len(elems)-1],
+	}, nil
+}
+
+// urlRewrite is a url.<base>.insteadOf setting, which replaces prefix with
+// base in remote URLs.
+type urlRewrite struct {
+	base, prefix string
+}
+
+// parseURLRewrites parses the output of git config --get-regexp, with lines
+// such as "url.git@github.com:.insteadof https://github.com/".
+func parseURLRewrites(output string) []urlRewrite {
+	var rewrites []urlRewrite
+	for _, line := range strings.Split(output, "\n") {
+		key, prefix, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		base, ok := strings.CutSuffix(strings.TrimPrefix(key, "url."), ".insteadof")
+		if !ok {
+			continue
+		}
+		rewrites = append(rewrites, urlRewrite{base: base, prefix: prefix})
+	}
+	return rewrites
+}
+
+// rewriteURL applies the rewrite with the longest matching prefix, like git
+// does.
+func rewriteURL(rawURL string, rewrites []urlRewrite) string {
+	var longest *urlRewrite
+	for i, rewrite := range rewrites {
+		if strings.HasPrefix(rawURL, rewrite.prefix) && (longest == nil || len(rewrite.prefix) > len(longest.prefix)) {
+			longest = &rewrites[i]
+		}
+	}
+	if longest == nil {
+		return rawURL
+	}
+	return longest.base + strings.TrimPrefix(rawURL, longest.prefix)
+}
+
+// sshConfig holds the Host sections of ~/.ssh/config which set a HostName.
+type sshConfig []sshHost
+
+type sshHost struct {
+	patterns []string
+	hostName string
+}
+
+func loadSSHConfig() (sshConfig, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(home, ".ssh", "config"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseSSHConfig(f)
+}
+
+// parseSSHConfig reads the Host and HostName keywords of an ssh_config(5) file.
+// Match sections are skipped, since evaluating their criteria requires ssh.
+func parseSSHConfig(r io.Reader) (sshConfig, error) {
+	var (
+		config  sshConfig
+		current *sshHost
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Keyword and arguments are separated by whitespace or an equals
+		// sign.
+		i := strings.IndexAny(line, " \t=")
+		if i < 0 {
+			continue
+		}
+		keyword := line[:i]
+		args := strings.Trim(strings.TrimLeft(line[i:], " \t="), `"`)
+		switch strings.ToLower(keyword) {
+		case "host":
+			config = append(config, sshHost{patterns: strings.Fields(args)})
+			current = &config[len(config)-1]
+		case "match":
+			current = nil
+		case "hostname":
+			// As with any keyword, the first value obtained is used.
+			if current != nil && current.hostName == "" {
+				current.hostName = args
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// hostName returns the host name an alias resolves to, or the alias itself
+// if no Host section sets one.
+func (c sshConfig) hostName(alias string) string {
+	for _, host := range c {
+		if host.hostName != "" && host.matches(alias) {
+			return strings.ReplaceAll(host.hostName, "%h", alias)
+		}
+	}
+	return alias
+}
+
+func (h sshHost) matches(alias string) bool {
+	matched := false
+	for _, pattern := range h.patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		ok, _ := path.Match(strings.TrimPrefix(pattern, "!"), alias)
+		if ok && negated {
+			return false
+		}
+		matched = matched || ok
+	}
+	return matched
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
-	"os"
+	"io"
 	"os/exec"
 	"strings"
 	"text/tabwriter"
@@ -26,112 +26,140 @@ var (
 		Foreground(lipgloss.Color("15"))
 )
 
-func (c *Client) printNotificationHeader(notification Notification, pr int) error {
-	updatedAt, err := time.Parse(time.RFC3339, notification.UpdatedAt)
+// Renderer writes the values returned by [Client] to an output. It decouples
+// fetching from presentation, so that the same data can be shown in the
+// terminal or emitted in a machine-readable format.
+type Renderer interface {
+	RenderPullRequests(w io.Writer, pullRequests []PullRequestSummary) error
+	RenderConversation(w io.Writer, conversation *Conversation) error
+	RenderDiff(w io.Writer, files []FileDiff) error
+	RenderInbox(w io.Writer, items []InboxItem) error
+	RenderDraft(w io.Writer, draft *Draft) error
+	RenderChecks(w io.Writer, checks []Check) error
+}
+
+// NewRenderer returns the renderer for the given output format. Diffs rendered
+// as text are piped through diffTool.
+func NewRenderer(format Format, diffTool string) Renderer {
+	if format.IsText() {
+		return TerminalRenderer{diffTool: diffTool}
+	}
+	return FormatRenderer{format: format}
+}
+
+// TerminalRenderer renders colored, human-readable output.
+type TerminalRenderer struct {
+	diffTool string
+}
+
+// FormatRenderer renders machine-readable output such as JSON.
+type FormatRenderer struct {
+	format Format
+}
+
+func (r FormatRenderer) RenderPullRequests(w io.Writer, pullRequests []PullRequestSummary) error {
+	return writeFormatted(w, r.format, pullRequests, pullRequestRow)
+}
+
+func (r FormatRenderer) RenderConversation(w io.Writer, conversation *Conversation) error {
+	return writeFormatted(w, r.format, conversation.AllComments(), commentRow)
+}
+
+func (r FormatRenderer) RenderDiff(w io.Writer, files []FileDiff) error {
+	return writeFormatted(w, r.format, files, fileDiffRow)
+}
+
+func (r FormatRenderer) RenderInbox(w io.Writer, items []InboxItem) error {
+	return writeFormatted(w, r.format, items, inboxRow)
+}
+
+func (r FormatRenderer) RenderDraft(w io.Writer, draft *Draft) error {
+	return writeFormatted(w, r.format, draft.Comments, reviewCommentRow)
+}
+
+func (r FormatRenderer) RenderChecks(w io.Writer, checks []Check) error {
+	return writeFormatted(w, r.format, checks, checkRow)
+}
+
+// RenderInbox writes each notification with the latest comment that
+// triggered it. It only relies on exported fields, so that notifications
+// loaded from the offline store render the same.
+func (r TerminalRenderer) RenderInbox(w io.Writer, items []InboxItem) error {
+	markdown, err := newMarkdownRenderer()
 	if err != nil {
 		return err
 	}
-	header := fmt.Sprintf(blue.Render("%d: %s (%v)"), pr, notification.Subject.Title, time.Since(updatedAt))
-	fmt.Println(header)
+	for _, item := range items {
+		header := fmt.Sprintf(blue.Render("%d: %s (%v)"), item.Number, item.Title, time.Since(item.UpdatedAt))
+		fmt.Fprintln(w, header)
+		if item.LatestComment == nil {
+			continue
+		}
+		fmt.Fprintln(w)
+		if err := renderComment(w, markdown, item.LatestComment); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (c *Client) printPullRequests(pullRequestEdges []*PullRequestEdge) error {
+func (r TerminalRenderer) RenderPullRequests(w io.Writer, pullRequests []PullRequestSummary) error {
 	var differentRepositories bool
 	var repositoryName string
-	for _, edge := range pullRequestEdges {
+	for _, pr := range pullRequests {
 		if repositoryName == "" {
-			repositoryName = edge.Node.Repository.Name
+			repositoryName = pr.Repository
 		}
-		if repositoryName != edge.Node.Repository.Name {
+		if repositoryName != pr.Repository {
 			differentRepositories = true
 			break
 		}
 	}
 
-	fmt.Print("\r") // TODO: cross-platform
+	fmt.Fprint(w, "\r") // TODO: cross-platform
 
-	red := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("1"))
-	green := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("2"))
-	yellow := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("3"))
-
-	writer := tabwriter.NewWriter(os.Stdout, 3, 3, 3, ' ', 0)
-	for _, edge := range pullRequestEdges {
-		var (
-			participating   bool
-			lastCommentByMe bool
-		)
-
-		pr := edge.Node
-		createdAt, err := time.Parse(time.RFC3339, string(pr.CreatedAt))
-		if err != nil {
-			return err
-		}
-		if pr.HeadRef != nil && len(pr.HeadRef.Name) > 15 {
-			pr.HeadRef.Name = pr.HeadRef.Name[:15] + "…"
+	writer := tabwriter.NewWriter(w, 3, 3, 3, ' ', 0)
+	for _, pr := range pullRequests {
+		login := pr.Author
+		if len(login) > 30 {
+			login = login[:30] + "…"
 		}
-		if len(pr.Author.Login) > 30 {
-			pr.Author.Login = pr.Author.Login[:30] + "…"
+		author := white.Render(login)
+		if pr.ViewerIsAuthor {
+			author = green.Render(login)
 		}
-		author := white.Render(pr.Author.Login)
-		if pr.Author.Login == c.login {
-			author = green.Render(c.login)
-		}
-
-		numComments := pr.Comments.TotalCount
-		for i, review := range pr.Reviews.Edges {
-			if review.Node.Body != "" {
-				numComments += 1
-			}
-			numComments += review.Node.Comments.TotalCount
 
-			if review.Node.Author.Login == c.login {
-				participating = true
-				if i == len(pr.Reviews.Edges)-1 {
-					lastCommentByMe = true
-				}
-			}
+		title := pr.Title
+		if len(title) > 80 {
+			title = title[:80] + "…"
 		}
 
-		if len(pr.Title) > 80 {
-			pr.Title = pr.Title[:80] + "…"
-		}
-
-		statusCheckIcon := green.Render("✓")
-		switch statusCheck(pr) {
-		case StatusStatePending, StatusStateExpected:
-			statusCheckIcon = yellow.Render("◯")
-		case StatusStateFailure, StatusStateError:
-			statusCheckIcon = red.Render("✗")
-		}
+		statusCheckIcon := statusIcon(pr.Status)
 
 		mailIcon := white.Render("🗨")
 
-		comments := white.Render(fmt.Sprintf("%3d", numComments))
-		if participating {
+		comments := white.Render(fmt.Sprintf("%3d", pr.Comments))
+		if pr.Participating {
 			mailIcon = green.Render("🗨")
-			comments = green.Render(fmt.Sprintf("%3d", numComments))
-			if !lastCommentByMe {
+			comments = green.Render(fmt.Sprintf("%3d", pr.Comments))
+			if pr.AwaitingViewer {
 				mailIcon = yellow.Render("🗨")
-				comments = yellow.Render(fmt.Sprintf("%3d", numComments))
+				comments = yellow.Render(fmt.Sprintf("%3d", pr.Comments))
 			}
 		}
 
 		fmt.Fprintf(writer, "%s\t%s\t%s\t%v %s\t%v %s",
 			white.Render(fmt.Sprint(pr.Number)),
 			author,
-			white.Render(pr.Title),
+			white.Render(title),
 			comments,
 			mailIcon,
-			white.Render(getAge(createdAt, true)),
+			white.Render(getAge(pr.CreatedAt, true)),
 			statusCheckIcon,
 		)
 
 		if differentRepositories {
-			fmt.Fprintf(writer, "\t%s", white.Render(pr.Repository.Name))
+			fmt.Fprintf(writer, "\t%s", white.Render(pr.Repository))
 		}
 
 		fmt.Fprint(writer, "\n")
@@ -139,77 +167,143 @@ func (c *Client) printPullRequests(pullRequestEdges []*PullRequestEdge) error {
 	return writer.Flush()
 }
 
-func statusCheck(pr *PullRequest) StatusState {
-	if pr.Commits == nil {
-		return StatusStateSuccess
+func statusIcon(state StatusState) string {
+	switch state {
+	case StatusStatePending, StatusStateExpected:
+		return yellow.Render("◯")
+	case StatusStateFailure, StatusStateError:
+		return red.Render("✗")
 	}
-	for _, node := range pr.Commits.Nodes {
-		if node.Commit.Status == nil {
-			// Not every commit has a status associated with it.
-			continue
+	return green.Render("✓")
+}
+
+func (r TerminalRenderer) RenderChecks(w io.Writer, checks []Check) error {
+	if len(checks) == 0 {
+		fmt.Fprintln(w, "No checks reported")
+		return nil
+	}
+	writer := tabwriter.NewWriter(w, 3, 3, 3, ' ', 0)
+	for _, check := range checks {
+		name := check.Name
+		if check.Workflow != "" {
+			name = check.Workflow + " / " + check.Name
 		}
-		for _, context := range node.Commit.Status.Contexts {
-			if context.State != StatusStateSuccess {
-				return context.State
-			}
+		duration := ""
+		if d := check.Duration(); d > 0 {
+			duration = d.String()
 		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			statusIcon(check.Status()),
+			white.Render(name),
+			white.Render(strings.ToLower(check.State)),
+			white.Render(duration),
+			blue.Render(check.URL),
+		)
 	}
-	return StatusStateSuccess
+	return writer.Flush()
 }
 
-func printComments(pr *PullRequest, comments []*comment) error {
-	yellow := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("3"))
-
-	b, err := stylesheet.ReadFile("markdown.json")
+func (r TerminalRenderer) RenderConversation(w io.Writer, conversation *Conversation) error {
+	markdown, err := newMarkdownRenderer()
 	if err != nil {
 		return err
 	}
 
-	r, err := glamour.NewTermRenderer(
-		glamour.WithStylesFromJSONBytes(b),
-		glamour.WithWordWrap(80),
-	)
+	body, err := markdown.Render(conversation.Body)
 	if err != nil {
 		return err
 	}
 
-	body, err := r.Render(pr.Body)
-	if err != nil {
-		return err
+	fmt.Fprintf(w, "%s (%s)\n\n", yellow.Render(conversation.Author), yellow.Render(getAge(conversation.CreatedAt, false)))
+	fmt.Fprintf(w, "%s\n", body)
+
+	// Interleave the comments on the pull request with the review threads,
+	// placing each thread at the time it was started.
+	comments, threads := conversation.Comments, conversation.Threads
+	for len(comments) > 0 || len(threads) > 0 {
+		if len(threads) == 0 || (len(comments) > 0 && comments[0].CreatedAt.Before(threads[0].CreatedAt())) {
+			if err := renderComment(w, markdown, comments[0]); err != nil {
+				return err
+			}
+			comments = comments[1:]
+			continue
+		}
+		if err := renderThread(w, markdown, threads[0]); err != nil {
+			return err
+		}
+		threads = threads[1:]
 	}
+	return nil
+}
 
-	createdAt, err := time.Parse(time.RFC3339, string(pr.CreatedAt))
+func renderComment(w io.Writer, markdown *glamour.TermRenderer, comment *ConversationComment) error {
+	body, err := markdown.Render(strings.ReplaceAll(comment.Body, "\r\n", "\n"))
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s (%s)\n\n", yellow.Render(pr.Author.Login), yellow.Render(getAge(createdAt, false)))
-	fmt.Printf("%s\n", body)
+	header := fmt.Sprintf("%s (%s)", yellow.Render(comment.Author), yellow.Render(getAge(comment.CreatedAt, false)))
+	if comment.ID > 0 {
+		// The ID can be passed to commands such as reply and resolve.
+		header += " " + white.Render(fmt.Sprintf("#%d", comment.ID))
+	}
+	if comment.ReviewState != "" && comment.ReviewState != "COMMENTED" {
+		header += " " + strings.ToLower(strings.ReplaceAll(comment.ReviewState, "_", " "))
+	}
+	fmt.Fprintf(w, "%s\n\n", header)
+	if comment.Body == "" {
+		return nil
+	}
+	fmt.Fprintf(w, "%s\n", body)
+	return nil
+}
 
-	diffHunks := make(map[string]string)
-	for _, comment := range comments {
-		body := strings.ReplaceAll(comment.body, "\r\n", "\n")
-		body, err := r.Render(body)
+// renderThread writes a review thread with the diff hunk it refers to. Resolved
+// threads are collapsed into a single line, since their discussion has
+// concluded.
+func renderThread(w io.Writer, markdown *glamour.TermRenderer, thread *ReviewThread) error {
+	location := thread.Path
+	if thread.Line > 0 {
+		location = fmt.Sprintf("%s:%d", thread.Path, thread.Line)
+	}
+	if thread.IsOutdated {
+		location += " (outdated)"
+	}
+	if thread.IsResolved {
+		fmt.Fprintf(w, "%s %s %s\n\n",
+			green.Render("✓"),
+			blue.Render(location),
+			white.Render(fmt.Sprintf("resolved thread #%d, %d comments", thread.Ref(), len(thread.Comments))),
+		)
+		return nil
+	}
+	fmt.Fprintf(w, "%s %s\n\n", blue.Render(location), white.Render(fmt.Sprintf("thread #%d", thread.Ref())))
+	if hunk := thread.Comments[0].DiffHunk; hunk != "" {
+		diff, err := markdown.Render("```diff\n" + hunk + "\n```")
 		if err != nil {
 			return err
 		}
-		fmt.Printf("%s (%s)\n\n", yellow.Render(comment.author), yellow.Render(getAge(comment.createdAt, false)))
-
-		if comment.diffHunk != "" && diffHunks[comment.diffHunk] == "" {
-			diff, err := r.Render("```diff\n" + comment.diffHunk + "\n```")
-			if err != nil {
-				return err
-			}
-			fmt.Printf("%s\n\n", diff)
-			diffHunks[comment.diffHunk] = comment.diffHunk
+		fmt.Fprintf(w, "%s\n\n", diff)
+	}
+	for _, comment := range thread.Comments {
+		if err := renderComment(w, markdown, comment); err != nil {
+			return err
 		}
-
-		fmt.Printf("%s\n", body)
 	}
 	return nil
 }
 
-func printDiff(patches []fileResp) error {
+func newMarkdownRenderer() (*glamour.TermRenderer, error) {
+	b, err := stylesheet.ReadFile("markdown.json")
+	if err != nil {
+		return nil, err
+	}
+	return glamour.NewTermRenderer(
+		glamour.WithStylesFromJSONBytes(b),
+		glamour.WithWordWrap(80),
+	)
+}
+
+func (r TerminalRenderer) RenderDiff(w io.Writer, patches []FileDiff) error {
 	var b bytes.Buffer
 
 	for _, file := range patches {
@@ -235,48 +329,62 @@ func printDiff(patches []fileResp) error {
 				fmt.Fprintf(&b, "+++ b/%s\n", file.Filename)
 			}
 		default:
-			return fmt.Errorf("printDiff: unhandled file status: %s", file.Status)
+			return fmt.Errorf("RenderDiff: unhandled file status: %s", file.Status)
 		}
 		if file.Patch != "" {
 			fmt.Fprintln(&b, file.Patch)
 		}
 	}
 
-	cmd := exec.Command("delta")
+	if r.diffTool == "" {
+		_, err := w.Write(b.Bytes())
+		return err
+	}
+	cmd := exec.Command("sh", "-c", r.diffTool)
 	cmd.Stdin = strings.NewReader(b.String())
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = w
 	if err := cmd.Run(); err != nil {
 		return err
 	}
 	return nil
 }
 
-func printDescription(pr *PullRequest) error {
-	user, ok := pr.Author.Value.(*User)
-	if !ok {
-		return fmt.Errorf("printDescription: unexpected type: %T", pr.Author.Value)
+// RenderDescription writes the title and description of a pull request
+// without its comments.
+func (r TerminalRenderer) RenderDescription(w io.Writer, conversation *Conversation) error {
+	author := conversation.AuthorName
+	if author == "" {
+		author = conversation.Author
 	}
+	fmt.Fprintln(w, yellow.Render(conversation.Title))
+	fmt.Fprintln(w, white.Render("Author:", author))
+	fmt.Fprintln(w, white.Render("Date:   "+conversation.CreatedAt.Format(time.RFC3339)))
+	fmt.Fprintln(w)
 
-	fmt.Println(yellow.Render(pr.Title))
-	fmt.Println(white.Render("Author:", *user.Name))
-	fmt.Println(white.Render("Date:   " + string(pr.CreatedAt)))
-	fmt.Println()
-
-	b, err := stylesheet.ReadFile("markdown.json")
+	markdown, err := newMarkdownRenderer()
 	if err != nil {
 		return err
 	}
-	r, err := glamour.NewTermRenderer(
-		glamour.WithWordWrap(80),
-		glamour.WithStylesFromJSONBytes(b),
-	)
+	description, err := markdown.Render(conversation.Body)
 	if err != nil {
 		return err
 	}
-	description, err := r.Render(pr.Body)
-	if err != nil {
-		return err
+	fmt.Fprintln(w, description)
+	return nil
+}
+
+func (r TerminalRenderer) RenderDraft(w io.Writer, draft *Draft) error {
+	if len(draft.Comments) == 0 {
+		fmt.Fprintf(w, "No pending comments for #%d\n", draft.Number)
+		return nil
+	}
+	for i, comment := range draft.Comments {
+		side := ""
+		if comment.Side == SideLeft {
+			side = " (left)"
+		}
+		fmt.Fprintf(w, "%s %s%s\n", yellow.Render(fmt.Sprintf("%d.", i+1)), blue.Render(comment.Location()), side)
+		fmt.Fprintf(w, "%s\n\n", white.Render(comment.Body))
 	}
-	fmt.Println(description)
 	return nil
 }
@@ -0,0 +1,75 @@
+package re
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// rerunTarget is a workflow run or check suite with failed checks. Check
+// suites not created by GitHub Actions have no run ID and can only be
+// re-requested as a whole.
+type rerunTarget struct {
+	RunID    int
+	SuiteID  int
+	Workflow string
+}
+
+// rerunTargets returns the workflow runs and check suites that have at least
+// one failed check, in the order they first appear.
+func rerunTargets(checks []Check) []rerunTarget {
+	var (
+		targets []rerunTarget
+		seen    = make(map[int]bool)
+	)
+	for _, check := range checks {
+		if check.SuiteID == 0 || seen[check.SuiteID] {
+			continue
+		}
+		if status := check.Status(); status != StatusStateFailure && status != StatusStateError {
+			continue
+		}
+		seen[check.SuiteID] = true
+		targets = append(targets, rerunTarget{
+			RunID:    check.RunID,
+			SuiteID:  check.SuiteID,
+			Workflow: check.Workflow,
+		})
+	}
+	return targets
+}
+
+// RerunWorkflowRun re-runs a GitHub Actions workflow run. If failedOnly is set,
+// only the failed jobs and the jobs depending on them are re-run.
+func (c *Client) RerunWorkflowRun(ctx context.Context, owner, repository string, runID int, failedOnly bool) error {
+	path := "rerun"
+	if failedOnly {
+		path = "rerun-failed-jobs"
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d/%s", c.endpoint, owner, repository, runID, path)
+	return c.post(ctx, url)
+}
+
+// RerequestCheckSuite asks the app that created a check suite to run it again.
+func (c *Client) RerequestCheckSuite(ctx context.Context, owner, repository string, suiteID int) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/check-suites/%d/rerequest", c.endpoint, owner, repository, suiteID)
+	return c.post(ctx, url)
+}
+
+// post sends a POST request without a body, as used by endpoints that trigger
+// an action.
+func (c *Client) post(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("%s: %w", url, newAPIError(resp))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package re
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRerunTargets(t *testing.T) {
+	checks := []Check{
+		{Name: "lint", SuiteID: 1, RunID: 10, Workflow: "CI", State: "SUCCESS"},
+		{Name: "test", SuiteID: 1, RunID: 10, Workflow: "CI", State: "FAILURE"},
+		{Name: "build", SuiteID: 1, RunID: 10, Workflow: "CI", State: "TIMED_OUT"},
+		{Name: "deploy", SuiteID: 2, Workflow: "Deploy", State: "FAILURE"},
+		{Name: "docs", SuiteID: 3, RunID: 30, Workflow: "Docs", State: "IN_PROGRESS"},
+		{Name: "ci/legacy", State: "FAILURE"},
+	}
+	want := []rerunTarget{
+		{RunID: 10, SuiteID: 1, Workflow: "CI"},
+		{SuiteID: 2, Workflow: "Deploy"},
+	}
+	if diff := cmp.Diff(rerunTargets(checks), want); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
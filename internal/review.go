@@ -0,0 +1,195 @@
+package re
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReviewComment is a comment anchored to one or more lines of a file in the
+// diff of a pull request. A single-line comment only sets Line, a multi-line
+// comment additionally sets StartLine to the first line of the range.
+type ReviewComment struct {
+	Path      string `json:"path"`
+	Body      string `json:"body"`
+	Line      int    `json:"line"`
+	Side      string `json:"side,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+}
+
+const (
+	// SideLeft refers to the deletions, i.e. the base of the diff.
+	SideLeft = "LEFT"
+	// SideRight refers to the additions or unchanged lines, i.e. the head of
+	// the diff.
+	SideRight = "RIGHT"
+)
+
+// NewReviewComment parses a location of the form <path>:<line> or
+// <path>:<start>-<end> and returns a comment anchored on the given side of the
+// diff.
+func NewReviewComment(location, side, body string) (ReviewComment, error) {
+	side = strings.ToUpper(side)
+	if side == "" {
+		side = SideRight
+	}
+	if side != SideLeft && side != SideRight {
+		return ReviewComment{}, fmt.Errorf("invalid side %q: must be %s or %s", side, SideLeft, SideRight)
+	}
+	i := strings.LastIndex(location, ":")
+	if i <= 0 || i == len(location)-1 {
+		return ReviewComment{}, fmt.Errorf("invalid location %q: expected <path>:<line>", location)
+	}
+	path, lines := location[:i], location[i+1:]
+
+	start, end, ok := strings.Cut(lines, "-")
+	line, err := strconv.Atoi(start)
+	if err != nil || line <= 0 {
+		return ReviewComment{}, fmt.Errorf("invalid line %q in location %q", start, location)
+	}
+	comment := ReviewComment{
+		Path: path,
+		Body: body,
+		Line: line,
+		Side: side,
+	}
+	if !ok {
+		return comment, nil
+	}
+	last, err := strconv.Atoi(end)
+	if err != nil || last <= 0 {
+		return ReviewComment{}, fmt.Errorf("invalid line %q in location %q", end, location)
+	}
+	if last < line {
+		return ReviewComment{}, fmt.Errorf("invalid range %q: end line before start line", lines)
+	}
+	if last == line {
+		return comment, nil
+	}
+	comment.StartLine = line
+	comment.StartSide = side
+	comment.Line = last
+	return comment, nil
+}
+
+// Location formats the lines the comment is anchored to in the same form
+// accepted by [NewReviewComment].
+func (c ReviewComment) Location() string {
+	if c.StartLine > 0 {
+		return fmt.Sprintf("%s:%d-%d", c.Path, c.StartLine, c.Line)
+	}
+	return fmt.Sprintf("%s:%d", c.Path, c.Line)
+}
+
+// hunk holds the line numbers of both sides that are part of a single hunk of
+// a patch. Only these lines can be commented on.
+type hunk struct {
+	left  map[int]bool
+	right map[int]bool
+}
+
+func (h hunk) contains(side string, line int) bool {
+	if side == SideLeft {
+		return h.left[line]
+	}
+	return h.right[line]
+}
+
+// parseHunks returns the hunks of a unified diff patch as returned by the
+// GitHub API for a single file.
+func parseHunks(patch string) ([]hunk, error) {
+	var (
+		hunks       []hunk
+		left, right int
+	)
+	scanner := bufio.NewScanner(strings.NewReader(patch))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "@@") {
+			var err error
+			left, right, err = parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			hunks = append(hunks, hunk{
+				left:  make(map[int]bool),
+				right: make(map[int]bool),
+			})
+			continue
+		}
+		if len(hunks) == 0 {
+			continue
+		}
+		h := hunks[len(hunks)-1]
+		switch {
+		case strings.HasPrefix(line, "-"):
+			h.left[left] = true
+			left++
+		case strings.HasPrefix(line, "+"):
+			h.right[right] = true
+			right++
+		case strings.HasPrefix(line, `\`):
+			// No newline at end of file.
+		default:
+			h.left[left] = true
+			h.right[right] = true
+			left++
+			right++
+		}
+	}
+	return hunks, scanner.Err()
+}
+
+// parseHunkHeader returns the start lines of both sides of a hunk header such
+// as "@@ -12,7 +12,8 @@ func main() {".
+func parseHunkHeader(header string) (int, int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0, 0, fmt.Errorf("parseHunkHeader: invalid header: %s", header)
+	}
+	left, err := parseHunkRange(fields[1], "-")
+	if err != nil {
+		return 0, 0, fmt.Errorf("parseHunkHeader: %w", err)
+	}
+	right, err := parseHunkRange(fields[2], "+")
+	if err != nil {
+		return 0, 0, fmt.Errorf("parseHunkHeader: %w", err)
+	}
+	return left, right, nil
+}
+
+func parseHunkRange(s, prefix string) (int, error) {
+	if !strings.HasPrefix(s, prefix) {
+		return 0, fmt.Errorf("invalid range: %s", s)
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(s, prefix), ",")
+	return strconv.Atoi(start)
+}
+
+// validateReviewComment verifies that the comment refers to a file of the pull
+// request and that all of its lines are part of the same hunk, which is what
+// the GitHub API requires to anchor the comment.
+func validateReviewComment(comment ReviewComment, files []FileDiff) error {
+	for _, file := range files {
+		if file.Filename != comment.Path {
+			continue
+		}
+		hunks, err := parseHunks(file.Patch)
+		if err != nil {
+			return err
+		}
+		for _, h := range hunks {
+			if !h.contains(comment.Side, comment.Line) {
+				continue
+			}
+			if comment.StartLine > 0 && !h.contains(comment.StartSide, comment.StartLine) {
+				return fmt.Errorf("%s: lines must be part of the same hunk of the diff", comment.Location())
+			}
+			return nil
+		}
+		return fmt.Errorf("%s: line is not part of the diff on the %s side", comment.Location(), strings.ToLower(comment.Side))
+	}
+	return fmt.Errorf("%s: file is not part of the pull request", comment.Path)
+}
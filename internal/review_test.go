@@ -0,0 +1,201 @@
+package re
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub"
+)
+
+func TestNewReviewComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		side     string
+		want     ReviewComment
+		wantErr  bool
+	}{
+		{
+			name:     "single-line",
+			location: "internal/git.go:12",
+			want:     ReviewComment{Path: "internal/git.go", Body: "nit", Line: 12, Side: SideRight},
+		},
+		{
+			name:     "multi-line",
+			location: "internal/git.go:12-15",
+			side:     "left",
+			want: ReviewComment{
+				Path:      "internal/git.go",
+				Body:      "nit",
+				Line:      15,
+				Side:      SideLeft,
+				StartLine: 12,
+				StartSide: SideLeft,
+			},
+		},
+		{
+			name:     "missing-line",
+			location: "internal/git.go",
+			wantErr:  true,
+		},
+		{
+			name:     "reversed-range",
+			location: "internal/git.go:15-12",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid-side",
+			location: "internal/git.go:12",
+			side:     "top",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewReviewComment(tt.location, tt.side, "nit")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateReviewComment(t *testing.T) {
+	files := []FileDiff{
+		{
+			Filename: "main.go",
+			Patch: `@@ -1,4 +1,5 @@
+ package main
+-import "fmt"
++import (
++	"fmt"
++)
+ func main() {
+@@ -20,2 +21,2 @@ func main() {
+ 	fmt.Println("a")
+-	fmt.Println("b")
++	fmt.Println("c")`,
+		},
+	}
+	tests := []struct {
+		location string
+		side     string
+		wantErr  bool
+	}{
+		{location: "main.go:3"},
+		{location: "main.go:2", side: SideLeft},
+		{location: "main.go:2-4"},
+		{location: "main.go:22"},
+		{location: "main.go:10", wantErr: true},
+		{location: "main.go:3-22", wantErr: true},
+		{location: "other.go:1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			comment, err := NewReviewComment(tt.location, tt.side, "nit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = validateReviewComment(comment, files)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestResolveReviewID(t *testing.T) {
+	fake := fakegithub.New(t, fakegithub.WithReviews(150))
+	client, err := NewClient(t.Context(), Config{Endpoint: fake.URL, RESTEndpoint: fake.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The review is on the second page of reviews.
+	id, err := client.resolveReviewID(t.Context(), "foo", "test-repo", 1, "142")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("PRR_142", id); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if _, err := client.resolveReviewID(t.Context(), "foo", "test-repo", 1, "151"); err == nil {
+		t.Error("got no error, want an error for a review that does not exist")
+	}
+}
+
+func TestValidateReviewCommentsPagination(t *testing.T) {
+	fake := fakegithub.New(t, fakegithub.WithFiles(142))
+	client, err := NewClient(t.Context(), Config{Endpoint: fake.URL, RESTEndpoint: fake.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := client.FetchDiff(t.Context(), "foo", "test-repo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(142, len(files)); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	// The file is on the second page of files.
+	comment := ReviewComment{Path: "file142.go", Body: "Nice", Line: 1, Side: SideRight}
+	if err := client.ValidateReviewComments(t.Context(), "foo", "test-repo", 1, comment); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	header := http.Header{}
+	header.Set("Link", `<https://api.github.com/repositories/1/pulls/1/files?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/1/pulls/1/files?per_page=100&page=3>; rel="last"`)
+	want := "https://api.github.com/repositories/1/pulls/1/files?per_page=100&page=2"
+	if diff := cmp.Diff(want, nextPageURL(header)); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if diff := cmp.Diff("", nextPageURL(http.Header{})); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestReviewComments(t *testing.T) {
+	review := func(id int32, state PullRequestReviewState, body string) *PullRequestReviewEdge {
+		return &PullRequestReviewEdge{Node: &PullRequestReview{
+			Author:     &Actor{Login: "bar"},
+			Body:       body,
+			CreatedAt:  "2006-01-02T15:04:05Z",
+			DatabaseId: &id,
+			State:      state,
+		}}
+	}
+	comments, err := reviewComments([]*PullRequestReviewEdge{
+		review(1, PullRequestReviewStateApproved, ""),
+		review(2, PullRequestReviewStateCommented, ""),
+		review(3, PullRequestReviewStateCommented, "Looks good"),
+		review(4, PullRequestReviewStateChangesRequested, ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, comment := range comments {
+		got = append(got, fmt.Sprintf("%d %s %q", comment.ID, comment.ReviewState, comment.Body))
+	}
+	// The review without a body that only groups review comments is left
+	// out, those that approve or request changes are kept.
+	want := []string{`1 APPROVED ""`, `3 COMMENTED "Looks good"`, `4 CHANGES_REQUESTED ""`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
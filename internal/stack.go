@@ -0,0 +1,535 @@
+package re
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// The branches of a stack record the branch they are stacked on and the
+// commit of that branch they were last rebased onto. Restacking needs the
+// latter once the parent was rewritten or squash-merged, since the commits
+// of the parent can then no longer be told apart by ancestry.
+const (
+	stackParentConfigKey = "re-stack-parent"
+	stackBaseConfigKey   = "re-stack-base"
+)
+
+// stackMarker delimits the navigation table in the body of a pull request, so
+// that it can be replaced without touching the rest of the body.
+const stackMarker = "<!-- re stack -->"
+
+// Stack is a chain of local branches, each based on the one below it, with
+// the bottom branch based on the default branch.
+type Stack struct {
+	// Trunk is the default branch of the base repository.
+	Trunk string
+	// TrunkRef is the remote-tracking branch of Trunk, such as upstream/main.
+	TrunkRef string
+	// Branches are ordered from the bottom to the top of the stack.
+	Branches []StackBranch
+}
+
+// StackBranch is a branch of a stack.
+type StackBranch struct {
+	Name string
+	// Parent is the branch this branch is based on, which is Trunk for the
+	// bottom branch.
+	Parent string
+	// PullRequest is nil if no pull request was opened for the branch yet.
+	PullRequest *BranchPullRequest
+}
+
+// ref returns the ref a branch of the stack is rebased onto.
+func (s *Stack) ref(branch string) string {
+	if branch == s.Trunk {
+		return s.TrunkRef
+	}
+	return branch
+}
+
+// DetectStack returns the stack that branch is part of. Each branch is stacked
+// on the branch recorded when the stack was last submitted or restacked, or
+// otherwise on the closest local branch it contains that is not merged into
+// trunk yet. Above branch, the stack continues as long as exactly one branch
+// is stacked on the top.
+func DetectStack(ctx context.Context, git Git, trunk, trunkRef, branch string) (*Stack, error) {
+	if branch == "" || branch == trunk {
+		return nil, fmt.Errorf("DetectStack: %q is not a branch stacked on %s", branch, trunk)
+	}
+	stack := &Stack{Trunk: trunk, TrunkRef: trunkRef}
+	chain := []StackBranch{}
+	for name := branch; name != trunk; {
+		parent, err := stackParent(ctx, git, name, trunk, trunkRef)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, StackBranch{Name: name, Parent: parent})
+		if slices.ContainsFunc(chain, func(b StackBranch) bool { return b.Name == parent }) {
+			return nil, fmt.Errorf("DetectStack: %s is stacked on itself", parent)
+		}
+		name = parent
+	}
+	slices.Reverse(chain)
+
+	for {
+		top := chain[len(chain)-1].Name
+		candidates, err := git.Branches(ctx, BranchFilter{Contains: top})
+		if err != nil {
+			return nil, err
+		}
+		var children []string
+		for _, candidate := range candidates {
+			if candidate == top || candidate == trunk {
+				continue
+			}
+			parent, err := stackParent(ctx, git, candidate, trunk, trunkRef)
+			if err != nil {
+				return nil, err
+			}
+			if parent == top {
+				children = append(children, candidate)
+			}
+		}
+		if len(children) == 0 {
+			break
+		}
+		if len(children) > 1 {
+			return nil, fmt.Errorf("DetectStack: more than one branch is stacked on %s: %s", top, strings.Join(children, ", "))
+		}
+		if slices.ContainsFunc(chain, func(b StackBranch) bool { return b.Name == children[0] }) {
+			return nil, fmt.Errorf("DetectStack: %s is stacked on itself", children[0])
+		}
+		chain = append(chain, StackBranch{Name: children[0], Parent: top})
+	}
+	stack.Branches = chain
+	return stack, nil
+}
+
+// stackParent returns the branch that a branch is stacked on.
+func stackParent(ctx context.Context, git Git, branch, trunk, trunkRef string) (string, error) {
+	parent, err := git.Config(ctx, "branch."+branch+"."+stackParentConfigKey)
+	if err != nil {
+		return "", err
+	}
+	if parent != "" && (parent == trunk || BranchExists(ctx, git, parent)) {
+		return parent, nil
+	}
+	// Branches already merged into trunk are left out, such as old feature
+	// branches that were never deleted.
+	candidates, err := git.Branches(ctx, BranchFilter{Merged: branch, NoMerged: trunkRef})
+	if err != nil {
+		return "", err
+	}
+	parent = trunk
+	closest, err := git.CountCommits(ctx, trunkRef, branch)
+	if err != nil {
+		return "", err
+	}
+	tip, err := git.RevParse(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range candidates {
+		if candidate == branch || candidate == trunk {
+			continue
+		}
+		// A branch pointing to the same commit could just as well be
+		// stacked on this one.
+		if commit, err := git.RevParse(ctx, candidate); err != nil || commit == tip {
+			continue
+		}
+		n, err := git.CountCommits(ctx, candidate, branch)
+		if err != nil {
+			return "", err
+		}
+		if n < closest {
+			parent, closest = candidate, n
+		}
+	}
+	return parent, nil
+}
+
+// record stores the parent of each branch and the commit it is based on,
+// unless they were recorded before.
+func (s *Stack) record(ctx context.Context, git Git) error {
+	for _, branch := range s.Branches {
+		key := "branch." + branch.Name + "."
+		parent, err := git.Config(ctx, key+stackParentConfigKey)
+		if err != nil {
+			return err
+		}
+		if parent == "" {
+			if err := git.SetConfig(ctx, key+stackParentConfigKey, branch.Parent); err != nil {
+				return err
+			}
+		}
+		base, err := git.Config(ctx, key+stackBaseConfigKey)
+		if err != nil {
+			return err
+		}
+		if base == "" {
+			base, err = git.MergeBase(ctx, s.ref(branch.Parent), branch.Name)
+			if err != nil {
+				return err
+			}
+			if err := git.SetConfig(ctx, key+stackBaseConfigKey, base); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stack detects the stack of the current branch along with the pull requests
+// of its branches.
+func (c *Command) stack(ctx context.Context) (*Stack, error) {
+	trunk, err := c.git.DefaultBranch(ctx, c.base.RemoteName)
+	if err != nil {
+		return nil, err
+	}
+	trunkRef := c.base.RemoteName + "/" + trunk
+	if _, err := c.git.RevParse(ctx, trunkRef); err != nil {
+		trunkRef = trunk
+	}
+	branch, err := c.git.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stack, err := DetectStack(ctx, c.git, trunk, trunkRef, branch)
+	if err != nil {
+		return nil, err
+	}
+	for i, branch := range stack.Branches {
+		pr, err := c.client.FindPullRequest(ctx, c.org, c.name, c.head.Owner+":"+branch.Name)
+		if err != nil {
+			return nil, err
+		}
+		stack.Branches[i].PullRequest = pr
+	}
+	return stack, nil
+}
+
+// PrintStack prints the stack of the current branch from the top down to the
+// default branch.
+func (c *Command) PrintStack(ctx context.Context) error {
+	stack, err := c.stack(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := c.git.CurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	for _, branch := range slices.Backward(stack.Branches) {
+		marker := " "
+		if branch.Name == current {
+			marker = "*"
+		}
+		line := marker + " " + branch.Name
+		if pr := branch.PullRequest; pr != nil {
+			line += fmt.Sprintf(" #%d %s", pr.Number, pr.Status())
+			if !pr.Merged && pr.Base != branch.Parent {
+				line += fmt.Sprintf(", based on %s instead of %s", pr.Base, branch.Parent)
+			}
+		}
+		fmt.Fprintln(c.out, line)
+	}
+	fmt.Fprintln(c.out, "  "+stack.Trunk)
+	return nil
+}
+
+// SubmitStack pushes the branches of the stack of the current branch and
+// opens a pull request for each branch that has none yet. Each pull request
+// is based on the branch below it, and its body lists the pull requests of
+// the stack.
+func (c *Command) SubmitStack(ctx context.Context) error {
+	// The base branch of a pull request has to be in the base repository, so
+	// a stack cannot be pushed to a fork.
+	if !c.head.SameRepository(c.base) {
+		return fmt.Errorf("SubmitStack: the branches of a stack have to be pushed to %s, not %s", c.base, c.head)
+	}
+	stack, err := c.stack(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stack.record(ctx, c.git); err != nil {
+		return err
+	}
+	for i, branch := range stack.Branches {
+		pr := branch.PullRequest
+		if pr != nil && pr.Merged {
+			fmt.Fprintf(c.out, "%s is merged as #%d, run re stack restack\n", branch.Name, pr.Number)
+			continue
+		}
+		if _, err := c.git.Push(ctx, c.head.RemoteName, branch.Name, true); err != nil {
+			return err
+		}
+		if pr == nil || pr.State != "open" {
+			title, body, err := CommitTitleAndBody(ctx, c.git, branch.Name)
+			if err != nil {
+				return err
+			}
+			args := CreatePullRequest{
+				Title: title,
+				Head:  branch.Name,
+				Base:  branch.Parent,
+				Body:  body,
+				Draft: true,
+			}
+			number, err := c.client.CreatePullRequest(ctx, c.org, c.name, args)
+			if err != nil {
+				return err
+			}
+			stack.Branches[i].PullRequest = &BranchPullRequest{
+				Number: number,
+				Title:  title,
+				Body:   body,
+				State:  "open",
+				Draft:  true,
+				Base:   branch.Parent,
+			}
+			fmt.Fprintf(c.out, "Created pull request %d for %s\n", number, branch.Name)
+			continue
+		}
+		if pr.Base != branch.Parent {
+			if err := c.client.UpdatePullRequest(ctx, c.org, c.name, pr.Number, UpdatePullRequest{Base: branch.Parent}); err != nil {
+				return err
+			}
+			pr.Base = branch.Parent
+			fmt.Fprintf(c.out, "Changed the base of #%d to %s\n", pr.Number, branch.Parent)
+		}
+		fmt.Fprintf(c.out, "Updated pull request %d for %s\n", pr.Number, branch.Name)
+	}
+
+	// The table can only be written once every branch has a pull request.
+	for i, branch := range stack.Branches {
+		pr := branch.PullRequest
+		if pr == nil || pr.State != "open" {
+			continue
+		}
+		body := withStackTable(pr.Body, stackTable(stack, i))
+		if body == pr.Body {
+			continue
+		}
+		if err := c.client.UpdatePullRequest(ctx, c.org, c.name, pr.Number, UpdatePullRequest{Body: body}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestackStack rebases each branch of the stack of the current branch onto
+// the current state of the branch below it. Branches whose pull requests were
+// merged are dropped from the stack and the branches above them are rebased
+// onto the default branch.
+func (c *Command) RestackStack(ctx context.Context) error {
+	current, err := c.git.CurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.git.Fetch(ctx, c.base.RemoteName); err != nil {
+		return err
+	}
+	stack, err := c.stack(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stack.record(ctx, c.git); err != nil {
+		return err
+	}
+	merged := make(map[string]string)
+	for _, branch := range stack.Branches {
+		if pr := branch.PullRequest; pr != nil && pr.Merged {
+			merged[branch.Name] = branch.Parent
+			fmt.Fprintf(c.out, "%s is merged as #%d, delete it with git branch -D %s\n", branch.Name, pr.Number, branch.Name)
+			continue
+		}
+		parent := branch.Parent
+		for merged[parent] != "" {
+			parent = merged[parent]
+		}
+		onto, err := c.git.RevParse(ctx, stack.ref(parent))
+		if err != nil {
+			return err
+		}
+		key := "branch." + branch.Name + "."
+		rebased, err := c.git.IsAncestor(ctx, onto, branch.Name)
+		if err != nil {
+			return err
+		}
+		if !rebased {
+			base, err := c.git.Config(ctx, key+stackBaseConfigKey)
+			if err != nil {
+				return err
+			}
+			if err := c.git.Rebase(ctx, onto, base, branch.Name); err != nil {
+				return fmt.Errorf("RestackStack: resolve the conflicts of %s, run git rebase --continue and then re stack restack again: %w", branch.Name, err)
+			}
+			fmt.Fprintf(c.out, "Restacked %s onto %s\n", branch.Name, parent)
+		}
+		if err := c.git.SetConfig(ctx, key+stackParentConfigKey, parent); err != nil {
+			return err
+		}
+		if err := c.git.SetConfig(ctx, key+stackBaseConfigKey, onto); err != nil {
+			return err
+		}
+	}
+	// Rebasing checks out each branch in turn.
+	return c.git.Switch(ctx, current, "")
+}
+
+// stackTable returns the navigation table for the pull request of the branch
+// at index current, listing the pull requests from the top of the stack down.
+func stackTable(stack *Stack, current int) string {
+	var b strings.Builder
+	b.WriteString(stackMarker + "\n")
+	b.WriteString("This pull request is part of a stack:\n\n")
+	b.WriteString("| | Pull request | Branch |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for i, branch := range slices.Backward(stack.Branches) {
+		marker := ""
+		if i == current {
+			marker = "→"
+		}
+		var number string
+		if pr := branch.PullRequest; pr != nil {
+			number = fmt.Sprintf("#%d", pr.Number)
+		}
+		fmt.Fprintf(&b, "| %s | %s | `%s` |\n", marker, number, branch.Name)
+	}
+	fmt.Fprintf(&b, "| | | `%s` |\n", stack.Trunk)
+	b.WriteString(stackMarker)
+	return b.String()
+}
+
+// withStackTable replaces the navigation table in the body of a pull request,
+// or appends it if there is none yet.
+func withStackTable(body, table string) string {
+	start := strings.Index(body, stackMarker)
+	if start < 0 {
+		if strings.TrimSpace(body) == "" {
+			return table
+		}
+		return strings.TrimRight(body, "\n") + "\n\n" + table
+	}
+	end := len(body)
+	if i := strings.Index(body[start+len(stackMarker):], stackMarker); i >= 0 {
+		end = start + len(stackMarker) + i + len(stackMarker)
+	}
+	return body[:start] + table + body[end:]
+}
+
+// BranchPullRequest is the pull request opened for a branch.
+type BranchPullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	// State is either open or closed.
+	State  string
+	Draft  bool
+	Merged bool
+	// Base is the name of the branch the pull request is based on.
+	Base string
+}
+
+// Status returns the state of the pull request, distinguishing draft and
+// merged pull requests.
+func (pr *BranchPullRequest) Status() string {
+	switch {
+	case pr.Merged:
+		return "merged"
+	case pr.State == "open" && pr.Draft:
+		return "draft"
+	}
+	return pr.State
+}
+
+type pullResp struct {
+	Number   int     `json:"number"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body"`
+	State    string  `json:"state"`
+	Draft    bool    `json:"draft"`
+	MergedAt *string `json:"merged_at"`
+	Base     struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// FindPullRequest returns the pull request opened for a head branch given as
+// owner:branch. An open pull request takes precedence over closed ones,
+// otherwise the most recent one is returned. It returns nil if there is none.
+func (c *Client) FindPullRequest(ctx context.Context, owner, repository, head string) (*BranchPullRequest, error) {
+	query := url.Values{"state": {"all"}, "head": {head}}
+	url := c.endpoint + "/repos/" + owner + "/" + repository + "/pulls?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+	var result []pullResp
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	found := result[0]
+	for _, pr := range result {
+		if pr.State == "open" {
+			found = pr
+			break
+		}
+	}
+	return &BranchPullRequest{
+		Number: found.Number,
+		Title:  found.Title,
+		Body:   found.Body,
+		State:  found.State,
+		Draft:  found.Draft,
+		Merged: found.MergedAt != nil,
+		Base:   found.Base.Ref,
+	}, nil
+}
+
+// UpdatePullRequest holds the fields of a pull request to change, empty
+// fields are left as they are.
+type UpdatePullRequest struct {
+	Base string `json:"base,omitempty"`
+	Body string `json:"body,omitempty"`
+}
+
+func (c *Client) UpdatePullRequest(ctx context.Context, owner, repository string, number int, args UpdatePullRequest) error {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.endpoint, owner, repository, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+	return nil
+}
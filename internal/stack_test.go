@@ -0,0 +1,82 @@
+package re
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectStack(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=re", "-c", "user.email=re@example.com"}, args...)...)
+		cmd.Dir = dir
+		if b, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, b)
+		}
+	}
+	git("init", "-q", "--initial-branch=main")
+	git("commit", "-q", "--allow-empty", "-m", "Initial commit")
+	for _, branch := range []string{"parser", "lexer", "printer"} {
+		git("switch", "-q", "--create", branch)
+		git("commit", "-q", "--allow-empty", "-m", "Add "+branch)
+	}
+	// Stale branches merged into main are not part of the stack.
+	git("branch", "old", "main")
+
+	want := []StackBranch{
+		{Name: "parser", Parent: "main"},
+		{Name: "lexer", Parent: "parser"},
+		{Name: "printer", Parent: "lexer"},
+	}
+	for _, branch := range []string{"parser", "lexer", "printer"} {
+		stack, err := DetectStack(t.Context(), NewExecGit(dir), "main", "main", branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, stack.Branches); diff != "" {
+			t.Errorf("diff: %s", diff)
+		}
+	}
+
+	git("switch", "-q", "--create", "formatter", "lexer")
+	git("commit", "-q", "--allow-empty", "-m", "Add formatter")
+	if _, err := DetectStack(t.Context(), NewExecGit(dir), "main", "main", "parser"); err == nil {
+		t.Error("got no error, want an error for a branch with two branches stacked on it")
+	}
+}
+
+func TestWithStackTable(t *testing.T) {
+	stack := &Stack{
+		Trunk: "main",
+		Branches: []StackBranch{
+			{Name: "parser", Parent: "main", PullRequest: &BranchPullRequest{Number: 12}},
+			{Name: "lexer", Parent: "parser", PullRequest: &BranchPullRequest{Number: 13}},
+		},
+	}
+	table := stackTable(stack, 0)
+	want := `<!-- re stack -->
+This pull request is part of a stack:
+
+| | Pull request | Branch |
+| --- | --- | --- |
+|  | #13 | ` + "`lexer`" + ` |
+| → | #12 | ` + "`parser`" + ` |
+| | | ` + "`main`" + ` |
+<!-- re stack -->`
+	if diff := cmp.Diff(want, table); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	body := withStackTable("Adds a parser.\n", table)
+	if diff := cmp.Diff("Adds a parser.\n\n"+table, body); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	// The table is replaced in place, keeping the text around it.
+	body = withStackTable(body+"\n\nFixes #7.", stackTable(stack, 1))
+	if diff := cmp.Diff("Adds a parser.\n\n"+stackTable(stack, 1)+"\n\nFixes #7.", body); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
@@ -0,0 +1,294 @@
+package re
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotSynced is returned by [Store] if the requested data has not been
+// synced yet.
+var ErrNotSynced = errors.New("not synced, run re sync")
+
+var (
+	repositoriesBucket  = []byte("repositories")
+	notificationsBucket = []byte("notifications")
+	queueBucket         = []byte("queue")
+)
+
+// Store is the local database that pull requests are synced into for offline
+// use. It is a bbolt database with a bucket per repository holding the open
+// pull requests along with their conversations and diffs, a bucket for the
+// notifications and a bucket queueing the reviews written offline.
+type Store struct {
+	path string
+}
+
+// Snapshot is the state of the open pull requests of a repository at the
+// time of a sync.
+type Snapshot struct {
+	Owner         string
+	Name          string
+	PullRequests  []PullRequestSummary
+	Conversations map[int]*Conversation
+	Diffs         map[int][]FileDiff
+}
+
+// QueuedReview is a review written offline, which is submitted on the next
+// sync.
+type QueuedReview struct {
+	ID       uint64                  `json:"-"`
+	Owner    string                  `json:"owner"`
+	Name     string                  `json:"name"`
+	Number   int                     `json:"number"`
+	Review   CreatePullRequestReview `json:"review"`
+	QueuedAt time.Time               `json:"queuedAt"`
+}
+
+// NewStore returns the store in the state directory.
+func NewStore() (*Store, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: filepath.Join(dir, "offline.db")}, nil
+}
+
+// open opens the database for a single transaction. The database is locked
+// while it is open, so it is not kept open between transactions.
+func (s *Store) open(readOnly bool) (*bolt.DB, error) {
+	if readOnly {
+		if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotSynced
+		}
+	} else if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: readOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Store: %s: %w", s.path, err)
+	}
+	return db, nil
+}
+
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// SaveSnapshot replaces everything stored for the repository of the snapshot,
+// so that pull requests closed since the last sync are dropped.
+func (s *Store) SaveSnapshot(snapshot Snapshot) error {
+	return s.update(func(tx *bolt.Tx) error {
+		repositories, err := tx.CreateBucketIfNotExists(repositoriesBucket)
+		if err != nil {
+			return err
+		}
+		key := []byte(snapshot.Owner + "/" + snapshot.Name)
+		if err := repositories.DeleteBucket(key); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		bucket, err := repositories.CreateBucket(key)
+		if err != nil {
+			return err
+		}
+		if err := put(bucket, "pulls", snapshot.PullRequests); err != nil {
+			return err
+		}
+		for number, conversation := range snapshot.Conversations {
+			if err := put(bucket, "conversation/"+strconv.Itoa(number), conversation); err != nil {
+				return err
+			}
+		}
+		for number, files := range snapshot.Diffs {
+			if err := put(bucket, "diff/"+strconv.Itoa(number), files); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PullRequests returns the open pull requests of a repository as of the last
+// sync.
+func (s *Store) PullRequests(owner, name string) ([]PullRequestSummary, error) {
+	var pullRequests []PullRequestSummary
+	err := s.viewRepository(owner, name, func(bucket *bolt.Bucket) error {
+		_, err := get(bucket, "pulls", &pullRequests)
+		return err
+	})
+	return pullRequests, err
+}
+
+// Conversation returns the conversation of a pull request as of the last sync.
+func (s *Store) Conversation(owner, name string, number int) (*Conversation, error) {
+	var conversation Conversation
+	err := s.viewRepository(owner, name, func(bucket *bolt.Bucket) error {
+		ok, err := get(bucket, "conversation/"+strconv.Itoa(number), &conversation)
+		if !ok && err == nil {
+			return fmt.Errorf("#%d is %w", number, ErrNotSynced)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// Diff returns the diff of a pull request as of the last sync.
+func (s *Store) Diff(owner, name string, number int) ([]FileDiff, error) {
+	var files []FileDiff
+	err := s.viewRepository(owner, name, func(bucket *bolt.Bucket) error {
+		ok, err := get(bucket, "diff/"+strconv.Itoa(number), &files)
+		if !ok && err == nil {
+			return fmt.Errorf("#%d is %w", number, ErrNotSynced)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (s *Store) viewRepository(owner, name string, fn func(bucket *bolt.Bucket) error) error {
+	return s.view(func(tx *bolt.Tx) error {
+		repositories := tx.Bucket(repositoriesBucket)
+		if repositories == nil {
+			return ErrNotSynced
+		}
+		bucket := repositories.Bucket([]byte(owner + "/" + name))
+		if bucket == nil {
+			return fmt.Errorf("%s/%s is %w", owner, name, ErrNotSynced)
+		}
+		return fn(bucket)
+	})
+}
+
+// SaveNotifications replaces the stored notifications.
+func (s *Store) SaveNotifications(items []InboxItem) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(notificationsBucket)
+		if err != nil {
+			return err
+		}
+		return put(bucket, "items", items)
+	})
+}
+
+// Notifications returns the notifications as of the last sync.
+func (s *Store) Notifications() ([]InboxItem, error) {
+	var items []InboxItem
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(notificationsBucket)
+		if bucket == nil {
+			return fmt.Errorf("notifications are %w", ErrNotSynced)
+		}
+		_, err := get(bucket, "items", &items)
+		return err
+	})
+	return items, err
+}
+
+// Enqueue adds a review to be submitted on the next sync.
+func (s *Store) Enqueue(review QueuedReview) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(queueBucket)
+		if err != nil {
+			return err
+		}
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(review)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(queueKey(id), b)
+	})
+}
+
+// Queue returns the queued reviews in the order they were written.
+func (s *Store) Queue() ([]QueuedReview, error) {
+	var reviews []QueuedReview
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queueBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var review QueuedReview
+			if err := json.Unmarshal(v, &review); err != nil {
+				return err
+			}
+			review.ID = binary.BigEndian.Uint64(k)
+			reviews = append(reviews, review)
+			return nil
+		})
+	})
+	if errors.Is(err, ErrNotSynced) {
+		return nil, nil
+	}
+	return reviews, err
+}
+
+// Dequeue removes a review from the queue once it has been submitted.
+func (s *Store) Dequeue(id uint64) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(queueBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete(queueKey(id))
+	})
+}
+
+// queueKey encodes the ID in big endian, so that the queue is iterated in
+// insertion order.
+func queueKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+func put(bucket *bolt.Bucket, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), b)
+}
+
+// get decodes the value of a key. It reports whether the key exists.
+func get(bucket *bolt.Bucket, key string, v any) (bool, error) {
+	b := bucket.Get([]byte(key))
+	if b == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(b, v)
+}
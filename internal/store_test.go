@@ -0,0 +1,156 @@
+package re
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStoreSnapshot(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "offline.db")}
+	if _, err := store.PullRequests("octocat", "hello"); !errors.Is(err, ErrNotSynced) {
+		t.Fatalf("got %v, want %v", err, ErrNotSynced)
+	}
+
+	createdAt := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	snapshot := Snapshot{
+		Owner: "octocat",
+		Name:  "hello",
+		PullRequests: []PullRequestSummary{
+			{Number: 1, Title: "Add greeting", Author: "octocat", CreatedAt: createdAt},
+			{Number: 2, Title: "Fix typo", Author: "hubot", CreatedAt: createdAt},
+		},
+		Conversations: map[int]*Conversation{
+			1: {Number: 1, Title: "Add greeting", Body: "Says hello.", CreatedAt: createdAt},
+		},
+		Diffs: map[int][]FileDiff{
+			1: {{Filename: "hello.go", Status: "added", Patch: "@@ -0,0 +1 @@\n+package hello", Changes: 1}},
+		},
+	}
+	if err := store.SaveSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	pullRequests, err := store.PullRequests("octocat", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(snapshot.PullRequests, pullRequests); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	conversation, err := store.Conversation("octocat", "hello", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(snapshot.Conversations[1], conversation); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	files, err := store.Diff("octocat", "hello", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(snapshot.Diffs[1], files); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	// A later sync drops the pull requests that were closed in between.
+	snapshot.PullRequests = snapshot.PullRequests[1:]
+	snapshot.Conversations = nil
+	snapshot.Diffs = nil
+	if err := store.SaveSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Diff("octocat", "hello", 1); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("got %v, want %v", err, ErrNotSynced)
+	}
+}
+
+func TestStoreQueue(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "offline.db")}
+	queue, err := store.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 0 {
+		t.Fatalf("got %d queued reviews, want none", len(queue))
+	}
+
+	for _, body := range []string{"first", "second", "third"} {
+		err := store.Enqueue(QueuedReview{
+			Owner:  "octocat",
+			Name:   "hello",
+			Number: 1,
+			Review: CreatePullRequestReview{Event: "COMMENT", Body: body},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	queue, err = store.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Dequeue(queue[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	queue, err = store.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, queued := range queue {
+		got = append(got, queued.Review.Body)
+	}
+	if diff := cmp.Diff([]string{"first", "third"}, got); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestStoreNotificationsRender(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "offline.db")}
+	items := []InboxItem{
+		{
+			Reason:        "comment",
+			Title:         "Add greeting",
+			Number:        1,
+			UpdatedAt:     time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			LatestComment: &ConversationComment{Author: "hubot", Body: "Looks good to me."},
+		},
+	}
+	if err := store.SaveNotifications(items); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Notifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Offline, the latest comment is rendered from what was stored.
+	var buf strings.Builder
+	if err := (TerminalRenderer{}).RenderInbox(&buf, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Looks good to me.") {
+		t.Errorf("got %q, want it to contain the latest comment", buf.String())
+	}
+}
+
+func TestCommentPullRequestOfflineEmpty(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "offline.db")}
+	command := &Command{out: io.Discard, org: "octocat", name: "hello", store: store, offline: true}
+	if err := command.CommentPullRequest(context.Background(), 1, ""); err == nil {
+		t.Fatal("got no error, want an error for an empty message")
+	}
+	queue, err := store.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 0 {
+		t.Errorf("got %d queued reviews, want none", len(queue))
+	}
+}
@@ -0,0 +1,140 @@
+package re
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sync submits the reviews queued offline and then stores the open pull
+// requests of the configured repositories, along with their conversations and
+// diffs, and the notifications for offline use. Without configured
+// repositories, the repository of the working directory is synced.
+func (c *Command) Sync(ctx context.Context) error {
+	repos := c.repos
+	if len(repos) == 0 {
+		if c.org == "" {
+			return errors.New("Sync: no repos configured and not in a repository with a GitHub remote")
+		}
+		repos = []string{c.org + "/" + c.name}
+	}
+	failed, err := c.submitQueuedReviews(ctx)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok {
+			return fmt.Errorf("Sync: invalid repo %q, expected owner/name", repo)
+		}
+		snapshot, err := c.fetchSnapshot(ctx, owner, name)
+		if err != nil {
+			return err
+		}
+		if err := c.store.SaveSnapshot(snapshot); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "Synced %d pull requests of %s\n", len(snapshot.PullRequests), repo)
+	}
+	items, err := c.client.FetchNotifiations(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.store.SaveNotifications(items); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Synced %d notifications\n", len(items))
+	if failed > 0 {
+		return fmt.Errorf("Sync: %d queued reviews could not be submitted and remain queued", failed)
+	}
+	return nil
+}
+
+func (c *Command) fetchSnapshot(ctx context.Context, owner, name string) (Snapshot, error) {
+	pullRequests, err := c.client.FetchPullRequests(ctx, 0, owner, name, false)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{
+		Owner:         owner,
+		Name:          name,
+		PullRequests:  pullRequests,
+		Conversations: make(map[int]*Conversation),
+		Diffs:         make(map[int][]FileDiff),
+	}
+	for _, pr := range pullRequests {
+		conversation, err := c.client.FetchComments(ctx, pr.Number, owner, name)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		files, err := c.client.FetchDiff(ctx, owner, name, pr.Number)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		snapshot.Conversations[pr.Number] = conversation
+		snapshot.Diffs[pr.Number] = files
+	}
+	return snapshot, nil
+}
+
+// submitQueuedReviews submits the reviews written offline. A review that
+// cannot be submitted, for example because the lines it comments on are no
+// longer part of the diff, is reported and kept in the queue. It returns the
+// number of such reviews.
+func (c *Command) submitQueuedReviews(ctx context.Context) (int, error) {
+	queue, err := c.store.Queue()
+	if err != nil {
+		return 0, err
+	}
+	var failed int
+	for _, queued := range queue {
+		ref := fmt.Sprintf("%s/%s#%d", queued.Owner, queued.Name, queued.Number)
+		err := c.client.ValidateReviewComments(ctx, queued.Owner, queued.Name, queued.Number, queued.Review.Comments...)
+		if err == nil {
+			err = c.client.SubmitReview(ctx, queued.Owner, queued.Name, queued.Number, queued.Review)
+		}
+		if err != nil {
+			fmt.Fprintf(c.out, "Could not submit queued comment on %s: %v\n", ref, err)
+			failed++
+			continue
+		}
+		if err := c.store.Dequeue(queued.ID); err != nil {
+			return failed, err
+		}
+		fmt.Fprintf(c.out, "Submitted queued comment on %s\n", ref)
+	}
+	return failed, nil
+}
+
+// queueReview stores a review written offline to be submitted on the next
+// sync.
+func (c *Command) queueReview(pr int, review CreatePullRequestReview) error {
+	err := c.store.Enqueue(QueuedReview{
+		Owner:    c.org,
+		Name:     c.name,
+		Number:   pr,
+		Review:   review,
+		QueuedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Queued comment on #%d, it is submitted on the next re sync\n", pr)
+	return nil
+}
+
+func (c *Command) listSyncedPullRequests(limit int, includeClosed bool) error {
+	if includeClosed {
+		return fmt.Errorf("closed pull requests are not synced for offline use")
+	}
+	pullRequests, err := c.store.PullRequests(c.org, c.name)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && limit < len(pullRequests) {
+		pullRequests = pullRequests[:limit]
+	}
+	return c.printPullRequests(pullRequests)
+}
@@ -0,0 +1,317 @@
+package re
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quota is the state of an API rate limit as last reported by GitHub.
+type Quota struct {
+	// Resource is the API the quota applies to: graphql for GraphQL queries
+	// and core for REST requests. They are limited separately.
+	Resource  string
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+	// Cost is the number of points the last GraphQL query consumed.
+	Cost int
+}
+
+// Low reports whether less than a tenth of the quota is left.
+func (q Quota) Low() bool {
+	return q.Limit > 0 && q.Remaining < q.Limit/10
+}
+
+// RateLimitError is returned when the API quota is exhausted and does not
+// reset soon enough to wait for it.
+type RateLimitError struct {
+	ResetAt time.Time
+	// Secondary is set for secondary rate limits, which GitHub imposes on
+	// bursts of requests regardless of the remaining quota.
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	if e.ResetAt.IsZero() {
+		return fmt.Sprintf("GitHub API %s exceeded, try again later", kind)
+	}
+	return fmt.Sprintf("GitHub API %s exceeded, resets at %s (in %s)",
+		kind, e.ResetAt.Local().Format(time.Kitchen), time.Until(e.ResetAt).Round(time.Second))
+}
+
+// retryTransport retries requests that failed due to rate limits or transient
+// server errors with exponential backoff. It honors the Retry-After and
+// X-RateLimit-Reset headers, and records the quota reported in responses.
+type retryTransport struct {
+	transport http.RoundTripper
+	// maxRetries is the number of times a request is retried.
+	maxRetries int
+	// maxWait is the longest time waited for before a retry. If the server
+	// asks to wait longer, a [RateLimitError] is returned instead.
+	maxWait time.Duration
+	sleep   func(ctx context.Context, d time.Duration) error
+
+	mu     sync.Mutex
+	quotas map[string]Quota
+}
+
+func newRetryTransport(transport http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		transport:  transport,
+		quotas:     make(map[string]Quota),
+		maxRetries: 4,
+		maxWait:    time.Minute,
+		sleep:      sleepContext,
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		// The request is cloned, since the transport below modifies it.
+		clone := req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return t.transport.RoundTrip(req)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			clone.Body = body
+		}
+		resp, err := t.transport.RoundTrip(clone)
+		if err != nil {
+			return nil, err
+		}
+		t.record(req, resp.Header)
+
+		wait, rateLimited, err := t.retryAfter(req, resp, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if wait < 0 {
+			return resp, nil
+		}
+		if wait > t.maxWait || attempt >= t.maxRetries {
+			if !rateLimited {
+				return resp, nil
+			}
+			resp.Body.Close()
+			return nil, &RateLimitError{
+				ResetAt:   time.Now().Add(wait),
+				Secondary: resp.Header.Get("X-RateLimit-Remaining") != "0",
+			}
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter returns how long to wait before retrying the request, or a
+// negative duration if the response must not be retried. It reports whether
+// the response was due to a rate limit.
+func (t *retryTransport) retryAfter(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool, error) {
+	backoff := time.Duration(1<<attempt) * time.Second
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// The request may have taken effect before the server failed, so
+		// only requests that can safely be repeated are retried.
+		ok, err := idempotent(req)
+		if err != nil || !ok {
+			return -1, false, err
+		}
+		return backoff, false, nil
+	case http.StatusForbidden, http.StatusTooManyRequests:
+	case http.StatusOK:
+		// GraphQL reports an exhausted quota with a successful status code.
+		if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+			return -1, false, nil
+		}
+		rateLimited, err := isGraphQLRateLimited(resp)
+		if err != nil || !rateLimited {
+			return -1, false, err
+		}
+	default:
+		return -1, false, nil
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0), true, nil
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return max(time.Until(date), 0), true, nil
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err == nil {
+			return max(time.Until(time.Unix(reset, 0)), 0), true, nil
+		}
+	}
+	// Secondary rate limits may come without either header. GitHub asks to
+	// wait at least a minute before retrying then.
+	secondary, err := isSecondaryRateLimited(resp)
+	if err != nil {
+		return -1, false, err
+	}
+	if secondary {
+		return max(secondaryRateLimitWait, backoff), true, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return backoff, true, nil
+	}
+	// Any other 403 is a permission error, which retrying will not resolve.
+	return -1, false, nil
+}
+
+// secondaryRateLimitWait is the least time to wait after hitting a secondary
+// rate limit without being told how long.
+const secondaryRateLimitWait = time.Minute
+
+// idempotent reports whether a request can be sent again without changing
+// state twice. GraphQL queries are sent with POST, only mutations change
+// state.
+func idempotent(req *http.Request) (bool, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true, nil
+	case http.MethodPost:
+	default:
+		return false, nil
+	}
+	if !strings.HasSuffix(req.URL.Path, "/graphql") || req.GetBody == nil {
+		return false, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return false, nil
+	}
+	return !isMutation(payload.Query), nil
+}
+
+// isGraphQLRateLimited reports whether the body of a GraphQL response contains
+// a RATE_LIMITED error.
+func isGraphQLRateLimited(resp *http.Response) (bool, error) {
+	b, err := peekBody(resp)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(b, []byte(`"RATE_LIMITED"`)), nil
+}
+
+// isSecondaryRateLimited reports whether the message of an error response
+// refers to a secondary rate limit or, as older GitHub Enterprise Server
+// versions call it, the abuse detection mechanism.
+func isSecondaryRateLimited(resp *http.Response) (bool, error) {
+	b, err := peekBody(resp)
+	if err != nil {
+		return false, err
+	}
+	message := bytes.ToLower(b)
+	return bytes.Contains(message, []byte("secondary rate limit")) || bytes.Contains(message, []byte("abuse")), nil
+}
+
+// peekBody reads the body of a response and restores it so that it can be
+// read again.
+func peekBody(resp *http.Response) ([]byte, error) {
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// record records the quota reported in the headers of a response to the
+// request, under the resource it applies to.
+func (t *retryTransport) record(req *http.Request, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	resource := header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+		if strings.HasSuffix(req.URL.Path, "/graphql") {
+			resource = "graphql"
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	quota := t.quotas[resource]
+	quota.Resource = resource
+	quota.Remaining = remaining
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		quota.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		quota.ResetAt = time.Unix(reset, 0)
+	}
+	t.quotas[resource] = quota
+}
+
+// recordCost records the cost of a GraphQL query as reported by its rateLimit
+// field.
+func (t *retryTransport) recordCost(rateLimit *RateLimit) {
+	if rateLimit == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	quota := t.quotas["graphql"]
+	quota.Resource = "graphql"
+	quota.Cost = int(rateLimit.Cost)
+	quota.Limit = int(rateLimit.Limit)
+	quota.Remaining = int(rateLimit.Remaining)
+	if resetAt, err := time.Parse(time.RFC3339, string(rateLimit.ResetAt)); err == nil {
+		quota.ResetAt = resetAt
+	}
+	t.quotas["graphql"] = quota
+}
+
+// Quotas returns the quotas recorded so far, ordered by resource.
+func (t *retryTransport) Quotas() []Quota {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.SortedFunc(maps.Values(t.quotas), func(a, b Quota) int {
+		return cmp.Compare(a.Resource, b.Resource)
+	})
+}
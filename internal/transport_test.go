@@ -0,0 +1,196 @@
+package re
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	tests := []struct {
+		name string
+		// query is the GraphQL document sent, a query unless set.
+		query string
+		// body is the body of every response.
+		body          string
+		responses     []*http.Response
+		wantWaits     []time.Duration
+		wantErr       bool
+		wantSecondary bool
+	}{
+		{
+			name: "bad-gateway",
+			responses: []*http.Response{
+				{StatusCode: http.StatusBadGateway},
+				{StatusCode: http.StatusBadGateway},
+				{StatusCode: http.StatusOK},
+			},
+			wantWaits: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:  "bad-gateway-mutation",
+			query: "mutation { mergePullRequest }",
+			responses: []*http.Response{
+				{StatusCode: http.StatusBadGateway},
+			},
+		},
+		{
+			name:  "rate-limited-mutation",
+			query: "mutation { mergePullRequest }",
+			responses: []*http.Response{
+				{StatusCode: http.StatusTooManyRequests},
+				{StatusCode: http.StatusOK},
+			},
+			wantWaits: []time.Duration{time.Second},
+		},
+		{
+			name: "retry-after",
+			responses: []*http.Response{
+				{StatusCode: http.StatusForbidden, Header: http.Header{"Retry-After": {"3"}}},
+				{StatusCode: http.StatusOK},
+			},
+			wantWaits: []time.Duration{3 * time.Second},
+		},
+		{
+			name: "quota-exhausted",
+			responses: []*http.Response{
+				{StatusCode: http.StatusForbidden, Header: http.Header{
+					"X-Ratelimit-Remaining": {"0"},
+					"X-Ratelimit-Reset":     {reset},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "secondary-rate-limit",
+			body: `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`,
+			responses: []*http.Response{
+				{StatusCode: http.StatusForbidden},
+				{StatusCode: http.StatusOK},
+			},
+			wantWaits: []time.Duration{time.Minute},
+		},
+		{
+			name: "abuse-detection",
+			body: `{"message":"You have triggered an abuse detection mechanism. Please wait a few minutes before you try again."}`,
+			responses: []*http.Response{
+				{StatusCode: http.StatusForbidden},
+				{StatusCode: http.StatusForbidden},
+				{StatusCode: http.StatusForbidden},
+				{StatusCode: http.StatusForbidden},
+				{StatusCode: http.StatusForbidden},
+			},
+			wantWaits:     []time.Duration{time.Minute, time.Minute, time.Minute, time.Minute},
+			wantErr:       true,
+			wantSecondary: true,
+		},
+		{
+			name: "forbidden",
+			body: `{"message":"Resource not accessible by integration"}`,
+			responses: []*http.Response{
+				{StatusCode: http.StatusForbidden},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			transport := newRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				resp := tt.responses[requests]
+				requests++
+				if resp.Header == nil {
+					resp.Header = http.Header{}
+				}
+				resp.Body = io.NopCloser(strings.NewReader(tt.body))
+				return resp, nil
+			}))
+			var waits []time.Duration
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			query := tt.query
+			if query == "" {
+				query = "query { viewer { login } }"
+			}
+			body, err := json.Marshal(map[string]string{"query": query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = transport.RoundTrip(req)
+			var rateLimitErr *RateLimitError
+			if tt.wantErr != errors.As(err, &rateLimitErr) {
+				t.Fatalf("got error %v, want rate limit error: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr && rateLimitErr.Secondary != tt.wantSecondary {
+				t.Errorf("got secondary %v, want %v", rateLimitErr.Secondary, tt.wantSecondary)
+			}
+			if requests != len(tt.responses) {
+				t.Errorf("got %d requests, want %d", requests, len(tt.responses))
+			}
+			if diff := cmp.Diff(waits, tt.wantWaits); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRetryTransportQuotas(t *testing.T) {
+	transport := newRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{
+			"X-Ratelimit-Limit":     {"5000"},
+			"X-Ratelimit-Remaining": {"4900"},
+			"X-Ratelimit-Reset":     {"1136214245"},
+		}
+		if strings.HasSuffix(req.URL.Path, "/graphql") {
+			header.Set("X-Ratelimit-Remaining", "300")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody}, nil
+	}))
+	for _, url := range []string{"https://api.github.com/graphql", "https://api.github.com/user"} {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transport.recordCost(&RateLimit{Cost: 2, Limit: 5000, Remaining: 298, ResetAt: "2006-01-02T15:04:05Z"})
+
+	reset := time.Unix(1136214245, 0)
+	want := []Quota{
+		{Resource: "core", Limit: 5000, Remaining: 4900, ResetAt: reset},
+		{Resource: "graphql", Limit: 5000, Remaining: 298, ResetAt: reset, Cost: 2},
+	}
+	got := transport.Quotas()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+	if got[0].Low() || !got[1].Low() {
+		t.Errorf("got low %v and %v, want only the GraphQL quota to be low", got[0].Low(), got[1].Low())
+	}
+}
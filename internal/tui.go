@@ -0,0 +1,433 @@
+package re
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	red = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("1"))
+	green = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("2"))
+	selected = lipgloss.NewStyle().
+			Reverse(true)
+)
+
+// ReviewInteractively opens a full-screen interface to step through the files
+// of a pull request, read existing review comments next to the lines they
+// refer to, write new comments and submit a review.
+func (c *Client) ReviewInteractively(ctx context.Context, owner, name string, number int) error {
+	files, err := c.FetchDiff(ctx, owner, name, number)
+	if err != nil {
+		return err
+	}
+	conversation, err := c.fetchConversation(ctx, owner, name, number)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("ReviewInteractively: #%d has no changed files", number)
+	}
+	m := newReviewModel(ctx, c, owner, name, conversation, files)
+	_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	return err
+}
+
+type reviewMode int
+
+const (
+	modeBrowse reviewMode = iota
+	modeComment
+	modeApprove
+	modeRequestChanges
+)
+
+// diffLine is a single row rendered in the review interface. Rows that refer
+// to a line of the diff carry the line number and side needed to anchor a new
+// comment.
+type diffLine struct {
+	text string
+	side string
+	line int
+}
+
+type reviewModel struct {
+	ctx      context.Context
+	client   *Client
+	owner    string
+	name     string
+	pr       *Conversation
+	files    []FileDiff
+	comments map[string][]*ConversationComment
+
+	file   int
+	lines  []diffLine
+	cursor int
+	offset int
+	width  int
+	height int
+
+	mode   reviewMode
+	input  textarea.Model
+	status string
+}
+
+type reviewSubmittedMsg struct {
+	status  string
+	comment *ConversationComment
+	err     error
+}
+
+func newReviewModel(ctx context.Context, client *Client, owner, name string, pr *Conversation, files []FileDiff) *reviewModel {
+	input := textarea.New()
+	input.ShowLineNumbers = false
+	input.SetHeight(3)
+
+	byPath := make(map[string][]*ConversationComment)
+	for _, comment := range pr.AllComments() {
+		if comment.Path != "" {
+			byPath[comment.Path] = append(byPath[comment.Path], comment)
+		}
+	}
+	m := &reviewModel{
+		ctx:      ctx,
+		client:   client,
+		owner:    owner,
+		name:     name,
+		pr:       pr,
+		files:    files,
+		comments: byPath,
+		input:    input,
+	}
+	m.selectFile(0)
+	return m
+}
+
+func (m *reviewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *reviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.SetWidth(msg.Width)
+		m.scroll()
+		return m, nil
+	case reviewSubmittedMsg:
+		if msg.err != nil {
+			m.status = red.Render(msg.err.Error())
+			return m, nil
+		}
+		if msg.comment != nil {
+			m.comments[msg.comment.Path] = append(m.comments[msg.comment.Path], msg.comment)
+			m.render()
+		}
+		m.status = green.Render(msg.status)
+		return m, nil
+	case tea.KeyMsg:
+		if m.mode != modeBrowse {
+			return m.updateInput(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+func (m *reviewModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "j", "down":
+		m.moveCursor(1)
+	case "k", "up":
+		m.moveCursor(-1)
+	case "ctrl+d", "pgdown":
+		m.moveCursor(m.viewHeight() / 2)
+	case "ctrl+u", "pgup":
+		m.moveCursor(-m.viewHeight() / 2)
+	case "g", "home":
+		m.moveCursor(-len(m.lines))
+	case "G", "end":
+		m.moveCursor(len(m.lines))
+	case "n", "tab":
+		m.selectFile(m.file + 1)
+	case "p", "shift+tab":
+		m.selectFile(m.file - 1)
+	case "c":
+		if m.lines[m.cursor].line == 0 {
+			m.status = red.Render("select a line of the diff to comment on")
+			return m, nil
+		}
+		return m, m.startInput(modeComment)
+	case "a":
+		return m, m.startInput(modeApprove)
+	case "r":
+		return m, m.startInput(modeRequestChanges)
+	}
+	return m, nil
+}
+
+func (m *reviewModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeBrowse
+		m.input.Blur()
+		m.status = ""
+		return m, nil
+	case "ctrl+s":
+		body := strings.TrimSpace(m.input.Value())
+		mode := m.mode
+		m.mode = modeBrowse
+		m.input.Blur()
+		return m, m.submit(mode, body)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *reviewModel) startInput(mode reviewMode) tea.Cmd {
+	m.mode = mode
+	m.status = ""
+	m.input.Reset()
+	return m.input.Focus()
+}
+
+// submit sends the comment or review in the background so that the interface
+// stays responsive while waiting for the API.
+func (m *reviewModel) submit(mode reviewMode, body string) tea.Cmd {
+	number := m.pr.Number
+	switch mode {
+	case modeComment:
+		if body == "" {
+			m.status = red.Render("comment is empty")
+			return nil
+		}
+		line := m.lines[m.cursor]
+		path := m.files[m.file].Filename
+		return func() tea.Msg {
+			review := ReviewComment{
+				Path: path,
+				Body: body,
+				Line: line.line,
+				Side: line.side,
+			}
+			if err := m.client.CommentOnLines(m.ctx, m.owner, m.name, number, review); err != nil {
+				return reviewSubmittedMsg{err: err}
+			}
+			return reviewSubmittedMsg{
+				status: "Commented on " + review.Location(),
+				comment: &ConversationComment{
+					Author:    m.client.login,
+					Body:      body,
+					CreatedAt: time.Now(),
+					Path:      path,
+					Line:      line.line,
+					Side:      line.side,
+				},
+			}
+		}
+	case modeApprove:
+		return func() tea.Msg {
+			if err := m.client.ReviewPullRequest(m.ctx, m.owner, m.name, number, "APPROVE", body); err != nil {
+				return reviewSubmittedMsg{err: err}
+			}
+			return reviewSubmittedMsg{status: fmt.Sprintf("Approved #%d", number)}
+		}
+	case modeRequestChanges:
+		if body == "" {
+			m.status = red.Render("requesting changes requires a message")
+			return nil
+		}
+		return func() tea.Msg {
+			if err := m.client.ReviewPullRequest(m.ctx, m.owner, m.name, number, "REQUEST_CHANGES", body); err != nil {
+				return reviewSubmittedMsg{err: err}
+			}
+			return reviewSubmittedMsg{status: fmt.Sprintf("Requested changes on #%d", number)}
+		}
+	}
+	return nil
+}
+
+func (m *reviewModel) selectFile(i int) {
+	if i < 0 || i >= len(m.files) {
+		return
+	}
+	m.file = i
+	m.cursor = 0
+	m.offset = 0
+	m.render()
+}
+
+func (m *reviewModel) moveCursor(delta int) {
+	m.cursor = max(0, min(len(m.lines)-1, m.cursor+delta))
+	m.scroll()
+}
+
+func (m *reviewModel) scroll() {
+	height := m.viewHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+}
+
+// viewHeight is the number of diff rows that fit between the header and the
+// footer of the interface.
+func (m *reviewModel) viewHeight() int {
+	footer := 2
+	if m.mode != modeBrowse {
+		footer += m.input.Height() + 1
+	}
+	return max(1, m.height-footer-2)
+}
+
+// diffAnchor identifies a line on one side of a diff.
+type diffAnchor struct {
+	side string
+	line int
+}
+
+// render lays out the patch of the current file and places existing comments
+// below the line they are anchored to, on the side of the diff they refer to.
+// Comments whose line is no longer part of the diff are shown at the end of
+// the file.
+func (m *reviewModel) render() {
+	file := m.files[m.file]
+	anchored := make(map[diffAnchor][]*ConversationComment)
+	var outdated []*ConversationComment
+	for _, comment := range m.comments[file.Filename] {
+		if comment.Line == 0 {
+			outdated = append(outdated, comment)
+			continue
+		}
+		anchor := diffAnchor{side: comment.Side, line: comment.Line}
+		if anchor.side == "" {
+			anchor.side = SideRight
+		}
+		anchored[anchor] = append(anchored[anchor], comment)
+	}
+
+	var lines []diffLine
+	var left, right int
+	attach := func(side string, line int) {
+		anchor := diffAnchor{side: side, line: line}
+		for _, comment := range anchored[anchor] {
+			lines = append(lines, renderThreadComment(comment)...)
+		}
+		delete(anchored, anchor)
+	}
+	for _, text := range strings.Split(file.Patch, "\n") {
+		switch {
+		case strings.HasPrefix(text, "@@"):
+			left, right, _ = parseHunkHeader(text)
+			lines = append(lines, diffLine{text: blue.Render(text)})
+		case strings.HasPrefix(text, "-"):
+			lines = append(lines, diffLine{
+				text: fmt.Sprintf("%4d      %s", left, red.Render(text)),
+				side: SideLeft,
+				line: left,
+			})
+			attach(SideLeft, left)
+			left++
+		case strings.HasPrefix(text, "+"):
+			lines = append(lines, diffLine{
+				text: fmt.Sprintf("     %4d %s", right, green.Render(text)),
+				side: SideRight,
+				line: right,
+			})
+			attach(SideRight, right)
+			right++
+		case strings.HasPrefix(text, `\`):
+			lines = append(lines, diffLine{text: white.Render(text)})
+		default:
+			lines = append(lines, diffLine{
+				text: fmt.Sprintf("%4d %4d %s", left, right, text),
+				side: SideRight,
+				line: right,
+			})
+			// Unchanged lines exist on both sides.
+			attach(SideLeft, left)
+			attach(SideRight, right)
+			left++
+			right++
+		}
+	}
+	for _, comments := range anchored {
+		outdated = append(outdated, comments...)
+	}
+	if len(outdated) > 0 {
+		lines = append(lines, diffLine{text: yellow.Render("Outdated comments")})
+		for _, comment := range outdated {
+			if comment.OriginalLine > 0 {
+				location := fmt.Sprintf("          │ originally on line %d", comment.OriginalLine)
+				lines = append(lines, diffLine{text: white.Render(location)})
+			}
+			lines = append(lines, renderThreadComment(comment)...)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, diffLine{text: white.Render("No changes to display")})
+	}
+	m.lines = lines
+	m.cursor = min(m.cursor, len(lines)-1)
+}
+
+func renderThreadComment(comment *ConversationComment) []diffLine {
+	header := fmt.Sprintf("          │ %s (%s)", yellow.Render(comment.Author), getAge(comment.CreatedAt, false))
+	lines := []diffLine{{text: header}}
+	body := strings.ReplaceAll(comment.Body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		lines = append(lines, diffLine{text: "          │ " + line})
+	}
+	return lines
+}
+
+func (m *reviewModel) View() string {
+	var b strings.Builder
+	file := m.files[m.file]
+	header := fmt.Sprintf("#%d %s", m.pr.Number, m.pr.Title)
+	fmt.Fprintln(&b, yellow.Render(header))
+	fmt.Fprintln(&b, blue.Render(fmt.Sprintf("[%d/%d] %s (%s)", m.file+1, len(m.files), file.Filename, file.Status)))
+
+	end := min(len(m.lines), m.offset+m.viewHeight())
+	for i := m.offset; i < end; i++ {
+		text := m.lines[i].text
+		if i == m.cursor && m.mode == modeBrowse {
+			text = selected.Render(">") + text
+		} else {
+			text = " " + text
+		}
+		fmt.Fprintln(&b, text)
+	}
+	for i := end - m.offset; i < m.viewHeight(); i++ {
+		fmt.Fprintln(&b)
+	}
+
+	switch m.mode {
+	case modeComment:
+		line := m.lines[m.cursor]
+		fmt.Fprintln(&b, yellow.Render(fmt.Sprintf("Comment on %s:%d (ctrl+s to submit, esc to cancel)", file.Filename, line.line)))
+		fmt.Fprintln(&b, m.input.View())
+	case modeApprove:
+		fmt.Fprintln(&b, yellow.Render("Approve with optional message (ctrl+s to submit, esc to cancel)"))
+		fmt.Fprintln(&b, m.input.View())
+	case modeRequestChanges:
+		fmt.Fprintln(&b, yellow.Render("Request changes (ctrl+s to submit, esc to cancel)"))
+		fmt.Fprintln(&b, m.input.View())
+	}
+	fmt.Fprintln(&b, m.status)
+	fmt.Fprint(&b, white.Render("j/k move • n/p file • c comment • a approve • r request changes • q quit"))
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package re
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReviewModelRender(t *testing.T) {
+	files := []FileDiff{
+		{
+			Filename: "main.go",
+			Status:   "modified",
+			Patch: `@@ -1,3 +1,3 @@
+ package main
+-var a = 1
++var a = 2
+ var b = 3`,
+		},
+	}
+	threads := []*ReviewThread{
+		{
+			Path:     "main.go",
+			Line:     2,
+			Comments: []*ConversationComment{{Author: "foo", Body: "why two?", Path: "main.go", Line: 2}},
+		},
+		{
+			Path:     "main.go",
+			Line:     2,
+			Side:     SideLeft,
+			Comments: []*ConversationComment{{Author: "foo", Body: "why not one?", Path: "main.go", Line: 2, Side: SideLeft}},
+		},
+		{
+			Path:         "main.go",
+			IsOutdated:   true,
+			OriginalLine: 7,
+			Comments:     []*ConversationComment{{Author: "bar", Body: "gone", Path: "main.go", OriginalLine: 7}},
+		},
+	}
+	m := newReviewModel(t.Context(), nil, "konradreiche", "re", &Conversation{Number: 1, Threads: threads}, files)
+
+	var got []string
+	for _, line := range m.lines {
+		got = append(got, line.text)
+	}
+	var (
+		removed  = indexOf(got, "-var a = 1")
+		added    = indexOf(got, "+var a = 2")
+		thread   = indexOf(got, "why two?")
+		outdated = indexOf(got, "Outdated comments")
+	)
+	if added < 0 || thread != added+2 {
+		t.Errorf("comment not anchored below line 2: %q", got)
+	}
+	if removed < 0 || indexOf(got, "why not one?") != removed+2 {
+		t.Errorf("comment not anchored below the deleted line 2: %q", got)
+	}
+	if outdated < thread || indexOf(got, "originally on line 7") < outdated || indexOf(got, "gone") < outdated {
+		t.Errorf("comment without line not shown as outdated: %q", got)
+	}
+	if line := m.lines[added]; line.side != SideRight || line.line != 2 {
+		t.Errorf("got %s:%d, want %s:2", line.side, line.line, SideRight)
+	}
+}
+
+func indexOf(lines []string, substr string) int {
+	for i, line := range lines {
+		if strings.Contains(line, substr) {
+			return i
+		}
+	}
+	return -1
+}
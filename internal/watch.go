@@ -0,0 +1,145 @@
+package re
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrChecksFailed is returned by [Command.WatchPullRequest] if at least
+	// one check failed.
+	ErrChecksFailed = errors.New("checks failed")
+	// ErrWatchTimeout is returned by [Command.WatchPullRequest] if the checks
+	// did not complete in time.
+	ErrWatchTimeout = errors.New("timed out waiting for checks")
+)
+
+// WatchOptions configures how a pull request is watched.
+type WatchOptions struct {
+	// Interval is the time between polls.
+	Interval time.Duration
+	// Timeout is the time after which watching is given up, zero waits
+	// indefinitely.
+	Timeout time.Duration
+	// Exec is a shell command run once the checks completed or timed out. The
+	// result is passed in the environment variable RE_WATCH_RESULT.
+	Exec string
+	// Bell rings the terminal bell once the checks completed or timed out.
+	Bell bool
+}
+
+// checkSummary counts the checks of a pull request by their condensed state.
+type checkSummary struct {
+	passed, pending, failed int
+}
+
+func summarizeChecks(checks []Check) checkSummary {
+	var summary checkSummary
+	for _, check := range checks {
+		switch check.Status() {
+		case StatusStateSuccess:
+			summary.passed++
+		case StatusStateFailure, StatusStateError:
+			summary.failed++
+		default:
+			summary.pending++
+		}
+	}
+	return summary
+}
+
+func (s checkSummary) String() string {
+	return fmt.Sprintf("%s %d passed  %s %d pending  %s %d failed",
+		statusIcon(StatusStateSuccess), s.passed,
+		statusIcon(StatusStatePending), s.pending,
+		statusIcon(StatusStateFailure), s.failed,
+	)
+}
+
+// WatchPullRequest polls the checks and review state of a pull request and
+// redraws a summary line until all checks completed. It returns
+// [ErrChecksFailed] if any check failed and [ErrWatchTimeout] if the checks
+// did not complete within the timeout.
+func (c *Command) WatchPullRequest(ctx context.Context, pr int, opts WatchOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	var deadline <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	start := time.Now()
+
+	var result error
+poll:
+	for {
+		checks, err := c.client.FetchChecks(ctx, c.org, c.name, pr)
+		if err != nil {
+			return err
+		}
+		decision, err := c.client.FetchReviewDecision(ctx, c.org, c.name, pr)
+		if err != nil {
+			return err
+		}
+		summary := summarizeChecks(checks)
+		review := "none"
+		if decision != "" {
+			review = strings.ToLower(strings.ReplaceAll(decision, "_", " "))
+		}
+		fmt.Fprintf(c.out, "\r#%d  %s  review: %s  (%s)", pr, summary, review, time.Since(start).Round(time.Second))
+
+		// Right after pushing, checks take a moment to be reported. Wait for
+		// them briefly before concluding that there are none.
+		waiting := len(checks) == 0 && time.Since(start) < 3*opts.Interval
+		if summary.pending == 0 && !waiting {
+			if summary.failed > 0 {
+				result = ErrChecksFailed
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(c.out)
+			return ctx.Err()
+		case <-deadline:
+			result = ErrWatchTimeout
+			break poll
+		case <-time.After(opts.Interval):
+		}
+	}
+	fmt.Fprintln(c.out)
+	if opts.Bell {
+		fmt.Fprint(c.out, "\a")
+	}
+	if opts.Exec != "" {
+		if err := runWatchHook(ctx, opts.Exec, result); err != nil {
+			return err
+		}
+	}
+	return result
+}
+
+func runWatchHook(ctx context.Context, command string, result error) error {
+	outcome := "success"
+	switch result {
+	case ErrChecksFailed:
+		outcome = "failure"
+	case ErrWatchTimeout:
+		outcome = "timeout"
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), "RE_WATCH_RESULT="+outcome)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("runWatchHook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package re_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	re "github.com/konradreiche/re/internal"
+	"github.com/konradreiche/re/internal/commandtest"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub"
+	"github.com/konradreiche/re/internal/commandtest/fakegithub/graph/model"
+)
+
+func TestWatchPullRequestCache(t *testing.T) {
+	fake := fakegithub.New(t, fakegithub.WithCheckRun("test", 1, model.CheckConclusionStateSuccess))
+	config := re.Config{
+		Endpoint:     fake.URL,
+		RESTEndpoint: fake.URL,
+		CacheDir:     t.TempDir(),
+	}
+	command, err := re.NewCommand(t.Context(), config, re.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The check completes on the second poll, well within the time GraphQL
+	// results are cached for.
+	opts := re.WatchOptions{Interval: time.Millisecond, Timeout: 5 * time.Second}
+	if err := command.WatchPullRequest(t.Context(), 1, opts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchPullRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		conclusion model.CheckConclusionState
+		want       error
+		wantResult string
+	}{
+		{
+			name:       "success",
+			conclusion: model.CheckConclusionStateSuccess,
+			wantResult: "success",
+		},
+		{
+			name:       "failure",
+			conclusion: model.CheckConclusionStateFailure,
+			want:       re.ErrChecksFailed,
+			wantResult: "failure",
+		},
+		{
+			// The check never completes.
+			name:       "timeout",
+			want:       re.ErrWatchTimeout,
+			wantResult: "timeout",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakegithub.New(t, fakegithub.WithCheckRun("test", 2, tt.conclusion))
+			command := commandtest.NewWithGitHub(t, fake, re.WithOutput(io.Discard))
+			result := filepath.Join(t.TempDir(), "result")
+			opts := re.WatchOptions{
+				Interval: time.Millisecond,
+				Timeout:  time.Second,
+				Exec:     `echo "$RE_WATCH_RESULT" > "` + result + `"`,
+			}
+			if err := command.WatchPullRequest(t.Context(), 1, opts); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			b, err := os.ReadFile(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantResult+"\n", string(b)); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}